@@ -0,0 +1,64 @@
+package godi
+
+import "fmt"
+
+// binding exposes the provider of T under the interface type I as well.
+// Lookups through I share T's id, so the singleton, hooks and circular
+// detection are the same as for T itself.
+type binding[I, T any] struct {
+	base Provider
+}
+
+// Provide matches everything the base provider matches, plus the interface I.
+func (b binding[I, T]) Provide(v any) (any, bool) {
+	id, ok := b.base.Provide(v)
+	if !ok {
+		_, ok = v.(*I)
+	}
+	return id, ok
+}
+
+// aliases returns the interface ids of the binding, including those of nested bindings.
+func (b binding[I, T]) aliases() []any {
+	return append(ids(b.base)[1:], (*I)(nil))
+}
+
+// inject resolves T through the base provider and converts it when I is requested.
+// T is looked up in c first, so an Override of T (e.g. in an Overlay) applies to I too.
+func (b binding[I, T]) inject(c *Container, ptr any) (any, error) {
+	ip, ok := ptr.(*I)
+	if !ok {
+		return b.base.inject(c, ptr)
+	}
+	p := b.base
+	if o, ok := c.providers.Load((*T)(nil)); ok && rankOf(o.(Provider)) == rankOverride {
+		p = o.(Provider)
+	}
+	var t T
+	v, err := p.inject(c, &t)
+	if err != nil {
+		return v, err
+	}
+	if *ip, ok = any(t).(I); !ok {
+		return nil, fmt.Errorf("bind %s error: %T does not implement it", typName(ptr), t)
+	}
+	return v, nil
+}
+
+// unwrap returns the base provider.
+func (b binding[I, T]) unwrap() Provider { return b.base }
+
+// target returns the injection target of the base provider.
+func (b binding[I, T]) target() any { return targetOf(b.base) }
+
+// Bind makes the provider p of T reachable as the interface I too.
+// Both lookups share the same singleton instance and fire hooks once for T.
+// Add reports conflicts between the interface and other providers of I.
+// Bind can be nested to expose several interfaces.
+// Example: Bind[UserRepository, *MySQLRepo](Build(NewMySQLRepo))
+func Bind[I, T any](p Provider) Provider {
+	if _, ok := p.Provide((*T)(nil)); !ok {
+		panic(fmt.Sprintf("bind %s: provider does not provide %s", typName((*I)(nil)), typName((*T)(nil))))
+	}
+	return binding[I, T]{p}
+}
@@ -0,0 +1,56 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// CanceledError is returned when the context of an injection is done
+// before the requested value is available.
+// It unwraps to the context error, so errors.Is(err, context.DeadlineExceeded) works.
+type CanceledError struct {
+	Type string   // Type requested when the context was done, e.g. main.Repo for a bound interface
+	Path []string // Types being resolved, outermost first, ending with Type
+	Err  error    // context.Canceled or context.DeadlineExceeded
+}
+
+func (e *CanceledError) Error() string {
+	msg := fmt.Sprintf("inject [%s] canceled: %v", e.Type, e.Err)
+	if len(e.Path) > 1 {
+		msg += fmt.Sprintf(" (resolving %s)", strings.Join(e.Path, " -> "))
+	}
+	return msg
+}
+
+func (e *CanceledError) Unwrap() error { return e.Err }
+
+// Context returns the context of the injection in progress.
+// Builders using the func(*Container) pattern can pass it to their own calls;
+// outside InjectCtx it is context.Background().
+func (c *Container) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+// withContext returns a context of c in which injections use ctx.
+func (c *Container) withContext(ctx context.Context) *Container {
+	tmp := c.fork(nil)
+	tmp.ctx = ctx
+	return tmp
+}
+
+// InjectToCtx is like InjectTo but ctx flows through the whole resolution chain:
+// BuildCtx constructors receive it, and the injection stops with a CanceledError
+// once ctx is done, including while waiting for a singleton built by another caller.
+func InjectToCtx[T any](ctx context.Context, c *Container, ptr *T) error {
+	return InjectTo(c.withContext(ctx), ptr)
+}
+
+// InjectCtx retrieves a dependency of type T using ctx for the whole resolution chain.
+// Example: db, err := InjectCtx[*sql.DB](ctx, c)
+func InjectCtx[T any](ctx context.Context, c *Container) (v T, _ error) {
+	return v, InjectToCtx(ctx, c, &v)
+}
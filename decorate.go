@@ -0,0 +1,97 @@
+package godi
+
+import (
+	"fmt"
+	"sort"
+	"sync/atomic"
+)
+
+// decoratorKey is the provider id of one decorator of T.
+type decoratorKey[T any] struct{ seq int64 }
+
+func (k decoratorKey[T]) sequence() int64 { return k.seq }
+
+// decorator wraps values of type T after their provider constructs them.
+type decorator[T any] struct {
+	decoratorKey[T]
+	at string
+	f  func(T) (T, error)
+}
+
+// Provide only matches the decorator's own id: decorators are never injected.
+func (d decorator[T]) Provide(v any) (any, bool) {
+	k, ok := v.(decoratorKey[T])
+	return d.decoratorKey, ok && k == d.decoratorKey
+}
+
+// decorates returns the id of T and where the decorator was registered. It marks decorators,
+// which wrap the values of T rather than provide a type (see walk).
+func (d decorator[T]) decorates() (id any, at string) { return (*T)(nil), d.at }
+
+// decorating is implemented by decorators of any type.
+type decorating interface {
+	sequenced
+	decorates() (id any, at string)
+}
+
+// decorators returns the decorators registered in c and its nested child containers,
+// in registration order.
+func (c *Container) decorators() (all []decorating) {
+	visited := make(map[*Container]bool)
+	var collect func(src *Container)
+	collect = func(src *Container) {
+		src.providers.Range(func(_, p any) bool {
+			switch p := p.(type) {
+			case decorating:
+				all = append(all, p)
+			case *Container:
+				if !visited[p] {
+					visited[p] = true
+					collect(p)
+				}
+			}
+			return true
+		})
+	}
+	collect(c)
+	sort.Slice(all, func(i, j int) bool { return all[i].sequence() < all[j].sequence() })
+	return
+}
+
+// constructs reports whether the provider at the end of the wrapper chain of p builds
+// values of the type identified by id, so its decorators apply. Bind aliases do not:
+// the value is built as the implementation type.
+func constructs(p Provider, id any) bool {
+	for u, ok := p.(interface{ unwrap() Provider }); ok; u, ok = p.(interface{ unwrap() Provider }) {
+		p = u.unwrap()
+	}
+	_, ok := p.Provide(id)
+	return ok
+}
+
+func (d decorator[T]) inject(*Container, any) (any, error) {
+	return nil, fmt.Errorf("decorator %s cannot be injected", typName((*T)(nil)))
+}
+
+// decorate applies every decorator of T visible from c to v, in registration order.
+func decorate[T any](c *Container, v T) (T, error) {
+	for _, cb := range gather[decorator[T]](c) {
+		var err error
+		if v, err = cb.p.f(v); err != nil {
+			return v, fmt.Errorf("decorate %s (%s) error: %w", typName((*T)(nil)), cb.p.at, err)
+		}
+	}
+	return v, nil
+}
+
+// Decorate creates a Provider that wraps every value of type T when it is constructed
+// (logging, metrics, caching...) without touching the module that registered T.
+// Decorators run after the base provider builds the value, so singletons are decorated once.
+// An interface exposed with Bind is not constructed as such: decorate the implementation
+// type instead. Validate reports decorators of a type no provider constructs.
+// Several decorators stack in registration order, and decorators registered in any container
+// of the injection path apply, including nested ones.
+// Example: Decorate(func(db *Database) (*Database, error) { return db.WithLogging(), nil })
+func Decorate[T any](f func(T) (T, error)) Provider {
+	return decorator[T]{decoratorKey[T]{atomic.AddInt64(&sequence, 1)}, location(1), f}
+}
@@ -0,0 +1,219 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+)
+
+// dependency is implemented by dependency forms (Deps2..Deps6, Named, Optional, Lazy).
+// InjectTo recognizes it and lets the form resolve its own fields from the container.
+type dependency interface {
+	resolve(c *Container) error
+}
+
+// resolve injects the dependency R of a factory function.
+// It handles the patterns supported by Build: struct{}, *Container,
+// dependency forms and single dependencies.
+func resolve[R any](c *Container) (v R, err error) {
+	switch pr := any(&v).(type) {
+	case *struct{}:
+		// No dependencies - empty struct
+	case **Container:
+		// Container access - inject container itself
+		*pr = c
+	default:
+		// Single dependency or dependency form - inject from container
+		err = InjectTo[R](c, &v)
+	}
+	return
+}
+
+// injectInto injects ptr unless a previous injection already failed.
+func injectInto[T any](c *Container, ptr *T, err *error) {
+	if *err == nil {
+		*err = InjectTo(c, ptr)
+	}
+}
+
+// Deps2 bundles two dependencies into a single Build argument.
+// Example: Build(func(d Deps2[Config, *Database]) (*Service, error) { ... })
+type Deps2[A, B any] struct {
+	A A
+	B B
+}
+
+func (d *Deps2[A, B]) declare() []requirement {
+	return concat(declare[A](), declare[B]())
+}
+
+func (d *Deps2[A, B]) resolve(c *Container) (err error) {
+	injectInto(c, &d.A, &err)
+	injectInto(c, &d.B, &err)
+	return
+}
+
+// Deps3 bundles three dependencies into a single Build argument.
+type Deps3[A, B, C any] struct {
+	A A
+	B B
+	C C
+}
+
+func (d *Deps3[A, B, C]) declare() []requirement {
+	return concat(declare[A](), declare[B](), declare[C]())
+}
+
+func (d *Deps3[A, B, C]) resolve(c *Container) (err error) {
+	injectInto(c, &d.A, &err)
+	injectInto(c, &d.B, &err)
+	injectInto(c, &d.C, &err)
+	return
+}
+
+// Deps4 bundles four dependencies into a single Build argument.
+type Deps4[A, B, C, D any] struct {
+	A A
+	B B
+	C C
+	D D
+}
+
+func (d *Deps4[A, B, C, D]) declare() []requirement {
+	return concat(declare[A](), declare[B](), declare[C](), declare[D]())
+}
+
+func (d *Deps4[A, B, C, D]) resolve(c *Container) (err error) {
+	injectInto(c, &d.A, &err)
+	injectInto(c, &d.B, &err)
+	injectInto(c, &d.C, &err)
+	injectInto(c, &d.D, &err)
+	return
+}
+
+// Deps5 bundles five dependencies into a single Build argument.
+type Deps5[A, B, C, D, E any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+}
+
+func (d *Deps5[A, B, C, D, E]) declare() []requirement {
+	return concat(declare[A](), declare[B](), declare[C](), declare[D](), declare[E]())
+}
+
+func (d *Deps5[A, B, C, D, E]) resolve(c *Container) (err error) {
+	injectInto(c, &d.A, &err)
+	injectInto(c, &d.B, &err)
+	injectInto(c, &d.C, &err)
+	injectInto(c, &d.D, &err)
+	injectInto(c, &d.E, &err)
+	return
+}
+
+// Deps6 bundles six dependencies into a single Build argument.
+type Deps6[A, B, C, D, E, F any] struct {
+	A A
+	B B
+	C C
+	D D
+	E E
+	F F
+}
+
+func (d *Deps6[A, B, C, D, E, F]) declare() []requirement {
+	return concat(declare[A](), declare[B](), declare[C](), declare[D](), declare[E](), declare[F]())
+}
+
+func (d *Deps6[A, B, C, D, E, F]) resolve(c *Container) (err error) {
+	injectInto(c, &d.A, &err)
+	injectInto(c, &d.B, &err)
+	injectInto(c, &d.C, &err)
+	injectInto(c, &d.D, &err)
+	injectInto(c, &d.E, &err)
+	injectInto(c, &d.F, &err)
+	return
+}
+
+// Optional is a dependency form that tolerates a missing provider of T.
+// Ok reports whether T is registered; a registered provider that fails still fails the build.
+// Example: Build(func(cache Optional[*Cache]) (*Service, error) { return NewService(cache.Value, cache.Ok), nil })
+type Optional[T any] struct {
+	Value T
+	Ok    bool
+}
+
+func (d *Optional[T]) declare() []requirement {
+	return []requirement{{id: (*T)(nil), optional: true}}
+}
+
+func (d *Optional[T]) resolve(c *Container) (err error) {
+	d.Value, d.Ok, err = InjectOptional[T](c)
+	return
+}
+
+// Lazy is a dependency form deferring the injection of T until Get is called.
+// The handle resolves against the container the injection was requested from, so expensive
+// dependencies are only built on first use and mutually-referencing services can
+// be wired as long as neither calls Get while being constructed.
+// It is safe for concurrent use.
+// Example: Build(func(repo Lazy[*Repo]) (*Service, error) { return &Service{repo: repo}, nil })
+type Lazy[T any] struct {
+	c     *Container
+	chain *resolution // Resolution chain when injected, to detect Get during construction
+}
+
+func (l *Lazy[T]) declare() []requirement {
+	return []requirement{{id: (*T)(nil), deferred: true}}
+}
+
+func (l *Lazy[T]) resolve(c *Container) error {
+	// Keep the live container rather than the temporary context, so providers added later
+	// stay visible
+	l.c, l.chain = c.requester(), c.resolving
+	return nil
+}
+
+// Get injects T from the container the handle was obtained from.
+// Singletons are built on the first call and shared afterwards.
+// The context of the injection that created the handle is not reused, see GetCtx.
+func (l Lazy[T]) Get() (T, error) { return l.GetCtx(context.Background()) }
+
+// GetCtx is like Get but uses ctx for the resolution chain (see InjectCtx).
+func (l Lazy[T]) GetCtx(ctx context.Context) (v T, err error) {
+	if l.c == nil {
+		return v, fmt.Errorf("lazy %s: not injected from a container", typName((*T)(nil)))
+	}
+	// Only the injections still running take part in the chain
+	tmp := l.c.withContext(ctx)
+	tmp.resolving = l.chain.live()
+	err = InjectTo(tmp, &v)
+	return
+}
+
+// Build2 creates a lazy singleton Provider from a constructor with two dependencies.
+// Example: Build2(func(cfg Config, db *Database) (*Service, error) { ... })
+func Build2[A, B, T any](f func(A, B) (T, error), opts ...BuildOption) Provider {
+	return Build(func(d Deps2[A, B]) (T, error) { return f(d.A, d.B) }, opts...)
+}
+
+// Build3 creates a lazy singleton Provider from a constructor with three dependencies.
+func Build3[A, B, C, T any](f func(A, B, C) (T, error), opts ...BuildOption) Provider {
+	return Build(func(d Deps3[A, B, C]) (T, error) { return f(d.A, d.B, d.C) }, opts...)
+}
+
+// Build4 creates a lazy singleton Provider from a constructor with four dependencies.
+func Build4[A, B, C, D, T any](f func(A, B, C, D) (T, error), opts ...BuildOption) Provider {
+	return Build(func(d Deps4[A, B, C, D]) (T, error) { return f(d.A, d.B, d.C, d.D) }, opts...)
+}
+
+// Build5 creates a lazy singleton Provider from a constructor with five dependencies.
+func Build5[A, B, C, D, E, T any](f func(A, B, C, D, E) (T, error), opts ...BuildOption) Provider {
+	return Build(func(d Deps5[A, B, C, D, E]) (T, error) { return f(d.A, d.B, d.C, d.D, d.E) }, opts...)
+}
+
+// Build6 creates a lazy singleton Provider from a constructor with six dependencies.
+func Build6[A, B, C, D, E, F, T any](f func(A, B, C, D, E, F) (T, error), opts ...BuildOption) Provider {
+	return Build(func(d Deps6[A, B, C, D, E, F]) (T, error) { return f(d.A, d.B, d.C, d.D, d.E, d.F) }, opts...)
+}
@@ -1,9 +1,11 @@
 package godi
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // must panics if the provided error is not nil.
@@ -16,10 +18,16 @@ func must(err error) {
 
 // typName returns the type name by extracting it from the formatted type string.
 // For example, "*godi.Database" becomes "godi.Database".
-func typName(ptr interface{}) string { return "[" + fmt.Sprintf("%T", ptr)[1:] + "]" }
+// Named providers render as [T "name"].
+func typName(ptr interface{}) string {
+	if q, ok := ptr.(interface{ qualifiedName() string }); ok {
+		return q.qualifiedName()
+	}
+	return "[" + fmt.Sprintf("%T", ptr)[1:] + "]"
+}
 
 // Provider is the interface that wraps the basic injection operations.
-// All providers (Provide, Build) must implement this interface.
+// All providers (Provide, Build, Factory) must implement this interface.
 type Provider interface {
 	// inject performs the actual dependency injection into the container.
 	inject(c *Container, ptr any) (v any, err error)
@@ -27,22 +35,60 @@ type Provider interface {
 	Provide(any) (any, bool)
 }
 
+// ids returns every id a provider answers to: its own id first,
+// followed by the interface aliases added by Bind.
+func ids(p Provider) []any {
+	id, _ := p.Provide(nil)
+	if b, ok := p.(interface{ aliases() []any }); ok {
+		return append([]any{id}, b.aliases()...)
+	}
+	return []any{id}
+}
+
 // provider is the concrete implementation of Provider for generic types.
 // It wraps a function that takes a container and pointer, returning the injected value.
-type provider[T any] func(*Container, *T) (T, error)
+type provider[T any] struct {
+	f func(*Container, *T) (T, error)
+	*info
+}
 
 // Provide returns type information for the provider.
 // It checks if the given value matches the provider's type T.
 func (p provider[T]) Provide(v any) (any, bool) { _, ok := v.(*T); return (*T)(nil), ok }
 
 // inject executes the provider function to inject the value into the pointer.
-func (p provider[T]) inject(c *Container, ptr any) (any, error) { return p(c, ptr.(*T)) }
+func (p provider[T]) inject(c *Container, ptr any) (any, error) { return p.f(c, ptr.(*T)) }
+
+// describe returns what the provider is known to do, see infoOf.
+func (p provider[T]) describe() *info { return p.info }
+
+// target returns a new injection target when the provider builds a lazy singleton, or nil.
+func (p provider[T]) target() any {
+	if p.kind == KindBuild {
+		return new(T)
+	}
+	return nil
+}
 
 // Provide creates a Provider that returns a pre-existing value.
 // This is used for simple values that don't require construction logic.
 // Example: Provide(Config{DSN: "mysql://localhost"})
 func Provide[T any](v T) Provider {
-	return provider[T](func(_ *Container, ptr *T) (T, error) { *ptr = v; return v, nil })
+	// l stores the value once decorated
+	l := new(instance)
+	return provider[T]{info: &info{kind: KindValue, l: l}, f: func(c *Container, ptr *T) (zero T, err error) {
+		defer recoverBuild(ptr, &err)
+		in, e := c.scope.singleton(l)
+		if e != nil {
+			return zero, e
+		}
+		value, e := in.do(c.Context(), ptr, nil, func() (any, error) { return decorate(c, v) })
+		if e != nil {
+			return zero, e
+		}
+		*ptr, _ = value.(T)
+		return *ptr, nil
+	}}
 }
 
 // Build creates a Provider that constructs a value lazily (on first use).
@@ -50,56 +96,109 @@ func Provide[T any](v T) Provider {
 //   - No dependencies: func(struct{}) (T, error)
 //   - Container access: func(*Container) (T, error)
 //   - Single dependency: func(Dependency) (T, error)
+//   - Multiple dependencies: func(Deps2[A, B]) (T, error), see also Build2..Build6
 //
 // The built value is cached (singleton pattern) after first construction.
+// Errors are cached too unless the Retry option is given.
 // Example: Build(func(c *Container) (*Database, error) { return NewDB() })
-func Build[R, T any](f func(R) (T, error)) Provider {
-	// l stores the lazy-initialized value with sync.Once for thread-safety
-	l := new(struct {
-		value T
-		once  sync.Once
-		err   error
-	})
-	return provider[T](func(c *Container, ptr *T) (zero T, err error) {
+func Build[R, T any](f func(R) (T, error), opts ...BuildOption) Provider {
+	return build(func(_ context.Context, v R) (T, error) { return f(v) }, opts)
+}
+
+// BuildCtx is like Build but f also receives the context of the injection
+// (see InjectCtx), for deadlines and cancellation while dialing or loading.
+// A construction failing while its context is done is not cached.
+// Example: BuildCtx(func(ctx context.Context, cfg Config) (*sql.DB, error) { return dial(ctx, cfg.DSN) })
+func BuildCtx[R, T any](f func(context.Context, R) (T, error), opts ...BuildOption) Provider {
+	return build(f, opts)
+}
+
+// build creates the lazy singleton Provider shared by Build and BuildCtx.
+func build[R, T any](f func(context.Context, R) (T, error), opts []BuildOption) Provider {
+	// l stores the lazy-initialized value, built once for thread-safety
+	l, o := new(instance), newBuildOptions(opts)
+	return provider[T]{info: &info{kind: KindBuild, deps: append(declare[R](), o.required()...), l: l}, f: func(c *Container, ptr *T) (zero T, err error) {
 		// Recover from panics and convert to errors
-		defer func() {
-			if e := recover(); e != nil {
-				err = fmt.Errorf("recovered from build %s panic: %v", typName(ptr), e)
-			}
-		}()
-		var v R
+		defer recoverBuild(ptr, &err)
 		// Handle different dependency patterns
-		switch pr := any(&v).(type) {
-		case *struct{}:
-			// No dependencies - empty struct
-		case **Container:
-			// Container access - inject container itself
-			*pr = c
-		default:
-			// Single dependency - inject from container
-			if e := InjectTo[R](c, &v); e != nil {
-				return zero, e
-			}
+		v, e := resolve[R](c)
+		if e != nil {
+			return zero, e
+		}
+		// Overlays build their own copy of the singleton
+		in, e := c.scope.singleton(l)
+		if e != nil {
+			return zero, buildError(c, ptr, e)
 		}
 		// Execute factory function once (singleton)
-		if l.once.Do(func() { l.value, l.err = f(v) }); l.err != nil {
-			return zero, fmt.Errorf("build %s error: %w", typName(ptr), l.err)
+		ctx := c.Context()
+		value, e := in.do(ctx, ptr, o, func() (any, error) {
+			value, err := f(ctx, v)
+			if err != nil {
+				return nil, err
+			}
+			return decorate(c, value)
+		})
+		if e != nil {
+			return zero, buildError(c, ptr, e)
 		}
-		*ptr = l.value
-		return l.value, nil
-	})
+		*ptr, _ = value.(T)
+		return *ptr, nil
+	}}
+}
+
+// Factory creates a Provider that constructs a new value on every injection (transient).
+// The factory function f supports the same dependency patterns as Build,
+// but its result is never cached: each Inject runs f again.
+// Hooks fire for every constructed value, with `provided` counting previous injections.
+// Nothing is cached, so only the Requires option applies.
+// Example: Factory(func(_ struct{}) (*bytes.Buffer, error) { return new(bytes.Buffer), nil })
+func Factory[R, T any](f func(R) (T, error), opts ...BuildOption) Provider {
+	return provider[T]{info: &info{kind: KindFactory, deps: append(declare[R](), newBuildOptions(opts).required()...)}, f: func(c *Container, ptr *T) (zero T, err error) {
+		defer recoverBuild(ptr, &err)
+		v, e := resolve[R](c)
+		if e != nil {
+			return zero, e
+		}
+		value, e := f(v)
+		if e == nil {
+			value, e = decorate(c, value)
+		}
+		if e != nil {
+			return zero, buildError(c, ptr, e)
+		}
+		*ptr = value
+		return value, nil
+	}}
+}
+
+// recoverBuild converts a panic raised while constructing ptr into an error.
+// It must be called directly by defer.
+func recoverBuild(ptr any, err *error) {
+	if e := recover(); e != nil {
+		*err = fmt.Errorf("recovered from build %s panic: %v", typName(ptr), e)
+	}
 }
 
 // Container is the core dependency injection container.
 // It manages providers, handles injection, and supports lifecycle hooks.
 // Thread-safe for concurrent access.
 type Container struct {
-	once      sync.Once // Reserved for future initialization logic
-	hooks     *sync.Map // Stores lifecycle hooks (Hook, HookOnce)
-	providers sync.Map  // Stores all registered providers
+	once      sync.Once          // Reserved for future initialization logic
+	hooks     *sync.Map          // Stores lifecycle hooks (Hook, HookOnce)
+	providers sync.Map           // Stores all registered providers
+	order     sync.Map           // Provider id → registration sequence (see Providers)
+	scope     *scope             // Scope owning Scoped instances (set by NewScope)
+	resolving *resolution        // Chain of types being injected (temporary contexts only)
+	ctx       context.Context    // Context of the injection (set by InjectCtx)
+	origin    *Container         // Container a temporary context was forked from
+	root      *Container         // Container the injection of a temporary context was requested from
+	lifecycle sync.Mutex         // Serializes Start and Stop
+	started   []component        // Components started by Start, in start order
+	stopped   map[*instance]bool // Components stopped by Start rollback (false) or shut down by Stop (true)
 }
 
-// locked is a sentinel value used to mark frozen containers.
+// locked is a sentinel value used to mark frozen containers and containers being modified.
 // When a container is added as a child, it becomes frozen and cannot accept new providers.
 var locked = &Container{}
 
@@ -110,26 +209,50 @@ var locked = &Container{}
 //
 // When adding a child container, it becomes frozen to prevent modification.
 func (c *Container) Add(pvs ...Provider) error {
-	// Acquire lock using atomic compare-and-swap pattern
-	// This prevents concurrent modifications and detects frozen state
-	for acquired, val := new(Container), any(nil); val != acquired; {
-		if val, _ = c.providers.LoadOrStore(locked, acquired); val == locked {
-			return errors.New("container frozen: already provided as child")
-		}
+	if err := c.lock(); err != nil {
+		return err
 	}
 	defer c.providers.Delete(locked)
 
 	// Register each provider
 	for _, p := range pvs {
-		id, _ := p.Provide(nil)
-		// Check for duplicate types
-		if typ, provided := c.Provide(id); provided {
-			return fmt.Errorf("provider %s already exists", typName(typ))
-		} else if sub, ok := id.(*Container); ok {
+		all := ids(p)
+		// Check for duplicate types, including interface aliases
+		for _, id := range all {
+			// Types being injected in a temporary context may be registered again
+			if typ, provided := c.Provide(id); provided && !c.resolving.has(typ) && !c.joins(p, id) {
+				err := &ContainerError{Err: ErrDuplicate, Type: typeOf(typ), Container: c}
+				if _, sub := id.(*Container); !sub && typName(id) != typName(typ) {
+					// Both registrations are described (e.g. map entries with their location)
+					err.Conflict = typeOf(id)
+				}
+				return err
+			}
+		}
+		if sub, ok := all[0].(*Container); ok {
 			// Mark child container as frozen
 			sub.providers.Store(locked, locked)
 		}
-		c.providers.Store(id, p)
+		c.register(all[0], p)
+	}
+	return nil
+}
+
+// register stores the provider p under id, recording the registration order.
+func (c *Container) register(id any, p Provider) {
+	c.order.Store(id, atomic.AddInt64(&sequence, 1))
+	c.providers.Store(id, p)
+}
+
+// lock acquires the container for modification; release it by deleting the locked key.
+// Returns an error if the container is frozen.
+func (c *Container) lock() error {
+	// Acquire lock using atomic compare-and-swap pattern
+	// This prevents concurrent modifications and detects frozen state
+	for acquired, val := new(Container), any(nil); val != acquired; {
+		if val, _ = c.providers.LoadOrStore(locked, acquired); val == locked {
+			return &ContainerError{Err: ErrFrozen, Container: c}
+		}
 	}
 	return nil
 }
@@ -146,7 +269,16 @@ func (c *Container) Provide(v any) (id any, ok bool) {
 		return c, false
 	} else if sub, is := v.(*Container); is {
 		// Recursively check child containers
-		sub.providers.Range(func(k, _ any) bool { id, ok = c.Provide(k); return !ok })
+		sub.providers.Range(func(_, p any) bool {
+			for _, k := range ids(p.(Provider)) {
+				// Group members of both containers join the same group
+				if id, ok = c.Provide(k); ok && !c.joins(p.(Provider), k) {
+					break
+				}
+				ok = false
+			}
+			return !ok
+		})
 	} else {
 		// Check direct providers
 		c.providers.Range(func(_, p any) bool { id, ok = p.(Provider).Provide(v); return !ok })
@@ -156,37 +288,147 @@ func (c *Container) Provide(v any) (id any, ok bool) {
 
 // inject is the internal injection logic that searches for providers.
 // It traverses the container hierarchy to find the appropriate provider.
+// Overrides win over direct providers, which win over child containers.
 func (c *Container) inject(parent *Container, ptr any) (value any, err error) {
-	ok := false
+	var found Provider
+	rank := 0
 	// Search through all providers in this container
-	if c.providers.Range(func(_, p any) bool {
+	c.providers.Range(func(_, p any) bool {
 		pv := p.(Provider)
-		if value, ok = pv.Provide(ptr); ok {
-			// Found provider - execute injection with circular dependency tracking
-			value, err = c.from(pv, value, ptr, parent)
+		if id, ok := pv.Provide(ptr); ok {
+			if r := rankOf(pv); r > rank {
+				found, value, rank = pv, id, r
+			}
+		}
+		return rank < rankOverride
+	})
+	if found == nil {
+		// No provider found, report the path of the injection leading here
+		chain := c.resolving
+		if parent != nil && parent.resolving != nil {
+			chain = parent.resolving
+		}
+		return nil, &ContainerError{
+			Err: ErrNotFound, Type: typeOf(ptr), Path: chain.path(ptr), Container: c.real(),
+			Suggestions: c.suggest(typeOf(ptr), nil, nil),
 		}
-		return !ok
-	}); !ok {
-		// No provider found
-		err = fmt.Errorf("provider %s not found", typName(ptr))
 	}
-	return
+	// Found provider - execute injection with circular dependency tracking
+	return c.from(found, value, ptr, parent)
 }
 
 // from executes the provider injection while tracking dependencies for circular detection.
 // It creates a temporary container context to track the dependency chain.
 func (c *Container) from(p Provider, id, ptr any, parent *Container) (v any, err error) {
-	// Check if this type is already being injected (circular dependency detection)
-	if stat, _ := c.providers.Load(id); stat == locked {
-		return nil, fmt.Errorf("circular dependency for %s ", typName(id))
+	// Create temporary container context for this injection
+	tmp := c.fork(parent)
+	outer := tmp.resolving
+	// Stop resolving the chain once the context of the injection is done
+	if e := tmp.Context().Err(); e != nil {
+		return nil, &CanceledError{Type: typeOf(ptr), Path: outer.path(ptr), Err: e}
+	}
+	// Child containers only forward the injection to the actual provider
+	if _, sub := p.(*Container); !sub {
+		// Record the dependency of the provider being injected (see Graph)
+		tmp.resolving.observe(id)
+		// Check if this type is already being injected (circular dependency detection)
+		if tmp.resolving.has(id) {
+			return nil, &ContainerError{Err: ErrCircular, Type: typeOf(id), Path: tmp.resolving.path(id), Container: tmp.real()}
+		}
+		// Mark current type as being injected
+		r := &resolution{id: id, p: p, parent: tmp.resolving}
+		defer r.finish()
+		tmp.resolving = r
 	}
 
-	// Create temporary container context for this injection
-	tmp := &Container{hooks: c.hooks}
+	// Execute the actual injection
+	if v, err = p.inject(tmp, ptr); err == nil && tmp.hooks != nil {
+		// Trigger hooks after successful injection
+		tmp.hooks.Range(func(_, h any) bool { h.(func(any, any))(id, v); return true })
+	}
+	// Name the type requested here when waiting for a singleton was canceled
+	var canceled *CanceledError
+	if err != nil && errors.As(err, &canceled) && canceled.Type == "" {
+		canceled.Type, canceled.Path = typeOf(ptr), outer.path(ptr)
+	}
+	return
+}
+
+// resolution is one link of the chain of types being injected, innermost first.
+type resolution struct {
+	id     any
+	p      Provider
+	parent *resolution
+	done   int32 // Set once the injection returned (contexts may outlive it, see Lazy)
+}
+
+// has reports whether id is still being injected somewhere in the chain.
+func (r *resolution) has(id any) bool {
+	for ; r != nil; r = r.parent {
+		if r.id == id && atomic.LoadInt32(&r.done) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// observe records that the provider of the link resolved the dependency id.
+func (r *resolution) observe(id any) {
+	if r == nil {
+		return
+	}
+	if i := infoOf(r.p); i != nil {
+		i.seen.Store(id, struct{}{})
+	}
+}
+
+// path returns the types of the chain, outermost first, followed by the type of id.
+func (r *resolution) path(id any) []string {
+	p := []string{typeOf(id)}
+	for ; r != nil; r = r.parent {
+		p = append(p, typeOf(r.id))
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// live returns the part of the chain still being injected. Links finish innermost
+// first, so it is the chain without its finished innermost links.
+func (r *resolution) live() *resolution {
+	for r != nil && atomic.LoadInt32(&r.done) == 1 {
+		r = r.parent
+	}
+	return r
+}
+
+// finish marks the injection of the link as completed.
+func (r *resolution) finish() { atomic.StoreInt32(&r.done, 1) }
+
+// fork creates a temporary container context holding the providers of c and parent.
+// Parent entries win over c's, and the innermost scope, resolution chain and context
+// of the injection path are kept.
+func (c *Container) fork(parent *Container) *Container {
+	tmp := &Container{hooks: c.hooks, scope: c.scope, resolving: c.resolving, ctx: c.ctx, origin: c.real(), root: c.requester()}
+	if parent != nil {
+		tmp.root = parent.requester()
+	}
+	if parent != nil && parent.resolving != nil {
+		tmp.resolving = parent.resolving
+	}
+	if parent != nil && parent.ctx != nil {
+		tmp.ctx = parent.ctx
+	}
+	if parent != nil && parent.scope != nil {
+		tmp.scope = parent.scope
+		// Containers resolved through an overlay keep their hooks untouched
+		if c.scope.isolated() == nil && tmp.scope.isolated() != nil {
+			tmp.hooks = nil
+		}
+	}
 	doCopy := func(k, v interface{}) bool {
-		if k == id {
-			tmp.providers.Store(k, locked) // Mark current type as being injected
-		} else if k != locked {
+		if k != locked {
 			tmp.providers.Store(k, v)
 		}
 		return true
@@ -196,13 +438,24 @@ func (c *Container) from(p Provider, id, ptr any, parent *Container) (v any, err
 	if c.providers.Range(doCopy); parent != nil {
 		parent.providers.Range(doCopy)
 	}
+	return tmp
+}
+
+// real returns the container a temporary context was forked from, or c itself.
+func (c *Container) real() *Container {
+	if c.origin != nil {
+		return c.origin
+	}
+	return c
+}
 
-	// Execute the actual injection
-	if v, err = p.inject(tmp, ptr); err == nil && tmp.hooks != nil {
-		// Trigger hooks after successful injection
-		tmp.hooks.Range(func(_, h any) bool { h.(func(any, any))(id, v); return true })
+// requester returns the container the injection of a temporary context was requested
+// from, or c itself.
+func (c *Container) requester() *Container {
+	if c.root != nil {
+		return c.root
 	}
-	return
+	return c.real()
 }
 
 // Inject injects dependencies into multiple pointers.
@@ -219,6 +472,10 @@ func (c *Container) Inject(ptrs ...any) error {
 // InjectTo injects a dependency into a specific pointer.
 // First checks if the type exists directly, otherwise searches the container hierarchy.
 func InjectTo[T any](c *Container, ptr *T) (err error) {
+	// Dependency forms (Deps2, Named...) resolve their own fields
+	if d, ok := any(ptr).(dependency); ok {
+		return d.resolve(c)
+	}
 	// Try direct provider first
 	if p, ok := c.providers.Load((*T)(nil)); ok {
 		_, err = c.from(p.(Provider), (*T)(nil), ptr, nil)
@@ -226,6 +483,11 @@ func InjectTo[T any](c *Container, ptr *T) (err error) {
 		// Search container hierarchy
 		_, err = c.inject(c, ptr)
 	}
+	// Missing T: suggest interface implementations too, which requires the type parameter
+	if e, ok := err.(*ContainerError); ok && e.Err == ErrNotFound && e.Type == typeOf(ptr) {
+		var zero T
+		e.Suggestions = c.suggest(e.Type, zero, func(v any) bool { _, ok := v.(T); return ok })
+	}
 	return
 }
 
@@ -239,6 +501,16 @@ func Inject[T any](c *Container) (v T, _ error) { return v, InjectTo[T](c, &v) }
 // MustInject is like Inject but panics on error and returns only the value.
 func MustInject[T any](c *Container) (v T) { MustInjectTo[T](c, &v); return }
 
+// InjectOptional retrieves a dependency of type T if it is registered.
+// It returns ok=false and no error when no provider of T is visible from c,
+// and ok=true with the injection error (if any) when the provider exists but fails.
+func InjectOptional[T any](c *Container) (v T, ok bool, err error) {
+	if _, ok = c.Provide((*T)(nil)); !ok {
+		return v, false, nil
+	}
+	return v, true, InjectTo[T](c, &v)
+}
+
 // InjectAs injects dependencies using non-generic interface.
 // Useful when generic syntax is not available or for dynamic types.
 func InjectAs(c *Container, ptrs ...any) (err error) { return c.Inject(ptrs...) }
@@ -2,12 +2,17 @@ package godi
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // Common test types
@@ -1439,6 +1444,2369 @@ func TestBuild_TableDriven(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Multi-Dependency Build Tests
+// =============================================================================
+
+func TestBuild_MultiDependency(t *testing.T) {
+	t.Run("Build2", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Provide(Database{DSN: "mysql://localhost"}),
+			Provide(Config{AppName: "app"}),
+			Build2(func(db Database, cfg Config) (Service, error) {
+				return Service{Name: "svc", DB: db, Cfg: cfg}, nil
+			}),
+		)
+		svc, err := Inject[Service](c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if svc.DB.DSN != "mysql://localhost" || svc.Cfg.AppName != "app" {
+			t.Errorf("unexpected service: %+v", svc)
+		}
+	})
+
+	t.Run("Build6", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Provide(bench0{Val: 1}), Provide(bench1{Val: 2}), Provide(bench2{Val: 3}),
+			Provide(bench3{Val: 4}), Provide(bench4{Val: 5}), Provide(bench5{Val: 6}),
+			Build6(func(a bench0, b bench1, c bench2, d bench3, e bench4, f bench5) (int, error) {
+				return a.Val + b.Val + c.Val + d.Val + e.Val + f.Val, nil
+			}),
+		)
+		if v, err := Inject[int](c); err != nil || v != 21 {
+			t.Errorf("expected 21, got %v, err %v", v, err)
+		}
+	})
+
+	t.Run("DepsTupleAcrossNestedContainers", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(Provide(Database{DSN: "child-db"}))
+		parent := &Container{}
+		parent.MustAdd(child, Provide(Config{AppName: "parent"}))
+		parent.MustAdd(Build(func(d Deps2[Database, Config]) (Service, error) {
+			return Service{DB: d.A, Cfg: d.B}, nil
+		}))
+		svc, err := Inject[Service](parent)
+		if err != nil || svc.DB.DSN != "child-db" || svc.Cfg.AppName != "parent" {
+			t.Errorf("unexpected service: %+v, err %v", svc, err)
+		}
+	})
+
+	t.Run("SingletonAndMissingDependency", func(t *testing.T) {
+		calls := 0
+		c := &Container{}
+		c.MustAdd(
+			Provide("name"),
+			Build2(func(s string, cfg Config) (Service, error) {
+				calls++
+				return Service{Name: s}, nil
+			}),
+		)
+		if _, err := Inject[Service](c); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+		c.MustAdd(Provide(Config{AppName: "late"}))
+		for i := 0; i < 3; i++ {
+			if _, err := Inject[Service](c); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected constructor called once, got %d", calls)
+		}
+	})
+
+	t.Run("CircularAndPanic", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Provide(Config{}),
+			Build2(func(cfg Config, s string) (int, error) { return len(s), nil }),
+			Build2(func(cfg Config, i int) (string, error) { return "", nil }),
+		)
+		if _, err := Inject[int](c); err == nil || !strings.Contains(err.Error(), "circular dependency") {
+			t.Errorf("expected circular dependency error, got %v", err)
+		}
+		c2 := &Container{}
+		c2.MustAdd(
+			Provide(Config{}), Provide("s"),
+			Build2(func(cfg Config, s string) (Database, error) { panic("boom") }),
+		)
+		if _, err := Inject[Database](c2); err == nil || !strings.Contains(err.Error(), "panic") {
+			t.Errorf("expected panic error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Factory (Transient) Tests
+// =============================================================================
+
+func TestFactory_Transient(t *testing.T) {
+	t.Run("NewValuePerInjection", func(t *testing.T) {
+		c := &Container{}
+		calls := 0
+		c.MustAdd(
+			Provide(Config{AppName: "app"}),
+			Factory(func(cfg Config) (*Service, error) {
+				calls++
+				return &Service{Name: cfg.AppName}, nil
+			}),
+		)
+		a := MustInject[*Service](c)
+		b := MustInject[*Service](c)
+		if a == b {
+			t.Error("expected distinct instances")
+		}
+		if calls != 2 || a.Name != "app" {
+			t.Errorf("expected 2 calls with name app, got %d calls, %+v", calls, a)
+		}
+	})
+
+	t.Run("HookProvidedCounts", func(t *testing.T) {
+		c := &Container{}
+		var counts []int
+		seen := map[*Service]bool{}
+		hook := c.Hook("each", func(v any, provided int) func(context.Context) {
+			counts = append(counts, provided)
+			seen[v.(*Service)] = true
+			return nil
+		})
+		c.MustAdd(Factory(func(_ struct{}) (*Service, error) { return &Service{}, nil }))
+		for i := 0; i < 3; i++ {
+			MustInject[*Service](c)
+		}
+		hook.Iterate(context.Background(), false)
+		if fmt.Sprint(counts) != "[0 1 2]" || len(seen) != 3 {
+			t.Errorf("expected provided [0 1 2] for 3 instances, got %v for %d", counts, len(seen))
+		}
+	})
+
+	t.Run("ErrorsCircularAndPanic", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Factory(func(s string) (int, error) { return len(s), nil }),
+			Factory(func(i int) (string, error) { return "", nil }),
+			Factory(func(_ struct{}) (Database, error) { return Database{}, fmt.Errorf("dial failed") }),
+			Factory(func(_ struct{}) (Config, error) { panic("boom") }),
+		)
+		if _, err := Inject[int](c); err == nil || !strings.Contains(err.Error(), "circular dependency") {
+			t.Errorf("expected circular dependency error, got %v", err)
+		}
+		if _, err := Inject[Database](c); err == nil || !strings.Contains(err.Error(), "dial failed") {
+			t.Errorf("expected build error, got %v", err)
+		}
+		if _, err := Inject[Config](c); err == nil || !strings.Contains(err.Error(), "panic") {
+			t.Errorf("expected panic error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Scope Tests
+// =============================================================================
+
+type scopedConn struct {
+	ID     int
+	closed *[]int
+}
+
+func (s *scopedConn) Close() error { *s.closed = append(*s.closed, s.ID); return nil }
+
+func TestScope_Lifetimes(t *testing.T) {
+	newRoot := func(closed *[]int) *Container {
+		ids := 0
+		root := &Container{}
+		root.MustAdd(
+			Build(func(_ struct{}) (*Database, error) { return &Database{DSN: "shared"}, nil }),
+			Scoped(func(db *Database) (*scopedConn, error) {
+				ids++
+				return &scopedConn{ID: ids, closed: closed}, nil
+			}),
+		)
+		return root
+	}
+
+	t.Run("OncePerScopeAndSharedSingletons", func(t *testing.T) {
+		var closed []int
+		root := newRoot(&closed)
+		s1, s2 := root.NewScope(), root.NewScope()
+		a1, a2 := MustInject[*scopedConn](s1), MustInject[*scopedConn](s1)
+		b := MustInject[*scopedConn](s2)
+		if a1 != a2 || a1 == b {
+			t.Errorf("expected one instance per scope, got %p %p %p", a1, a2, b)
+		}
+		if MustInject[*Database](s1) != MustInject[*Database](s2) {
+			t.Error("expected Build singleton shared between scopes")
+		}
+	})
+
+	t.Run("DisposeOnlyScopeOwned", func(t *testing.T) {
+		var closed []int
+		root := newRoot(&closed)
+		s1, s2 := root.NewScope(), root.NewScope()
+		MustInject[*scopedConn](s1)
+		MustInject[*scopedConn](s2)
+		if err := s1.Dispose(); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(closed) != "[1]" {
+			t.Errorf("expected only scope 1 instance closed, got %v", closed)
+		}
+		if _, err := Inject[*scopedConn](s1); err == nil || !strings.Contains(err.Error(), "disposed") {
+			t.Errorf("expected disposed error, got %v", err)
+		}
+		if err := root.Dispose(); err == nil {
+			t.Error("expected error disposing a non-scope container")
+		}
+	})
+
+	t.Run("RequiresScope", func(t *testing.T) {
+		var closed []int
+		root := newRoot(&closed)
+		if _, err := Inject[*scopedConn](root); err == nil || !strings.Contains(err.Error(), "requires a scope") {
+			t.Errorf("expected scope error, got %v", err)
+		}
+	})
+
+	t.Run("ScopeProvidersAndRootNotFrozen", func(t *testing.T) {
+		var closed []int
+		root := newRoot(&closed)
+		s := root.NewScope()
+		s.MustAdd(Provide(Config{AppName: "request"}))
+		if err := s.Add(Provide(Database{})); err != nil {
+			t.Fatal(err)
+		}
+		if err := root.Add(Provide("late")); err != nil {
+			t.Errorf("expected root to stay writable, got %v", err)
+		}
+		if v, err := Inject[string](s); err != nil || v != "late" {
+			t.Errorf("expected late, got %v, err %v", v, err)
+		}
+		if err := s.Add(Provide(&Database{})); err == nil {
+			t.Error("expected duplicate error for type registered in root")
+		}
+	})
+
+	t.Run("NestedScopeInChildContainer", func(t *testing.T) {
+		var closed []int
+		child := newRoot(&closed)
+		root := &Container{}
+		root.MustAdd(child)
+		outer := root.NewScope()
+		inner := outer.NewScope()
+		if MustInject[*scopedConn](outer) == MustInject[*scopedConn](inner) {
+			t.Error("expected nested scope to own its instance")
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var closed []int
+		s := newRoot(&closed).NewScope()
+		var wg sync.WaitGroup
+		results := make(chan *scopedConn, 50)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- MustInject[*scopedConn](s)
+			}()
+		}
+		wg.Wait()
+		close(results)
+		first := <-results
+		for v := range results {
+			if v != first {
+				t.Fatal("expected a single instance per scope")
+			}
+		}
+	})
+}
+
+// =============================================================================
+// Named Provider Tests
+// =============================================================================
+
+type replicaName struct{}
+
+func (replicaName) Name() string { return "replica" }
+
+type primaryName struct{}
+
+func (primaryName) Name() string { return "primary" }
+
+func TestNamed_Providers(t *testing.T) {
+	t.Run("MultipleInstancesOfOneType", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Provide(&Database{DSN: "default"}),
+			ProvideNamed("primary", &Database{DSN: "primary"}),
+			BuildNamed("replica", func(cfg Config) (*Database, error) { return &Database{DSN: cfg.AppName}, nil }),
+			Provide(Config{AppName: "replica"}),
+		)
+		for name, want := range map[string]string{"primary": "primary", "replica": "replica"} {
+			db, err := InjectNamed[*Database](c, name)
+			if err != nil || db.DSN != want {
+				t.Errorf("%s: expected %s, got %v, err %v", name, want, db, err)
+			}
+		}
+		if db := MustInject[*Database](c); db.DSN != "default" {
+			t.Errorf("expected unnamed default, got %s", db.DSN)
+		}
+		if MustInjectNamed[*Database](c, "replica") != MustInjectNamed[*Database](c, "replica") {
+			t.Error("expected named Build to be a singleton")
+		}
+	})
+
+	t.Run("DuplicateAndNotFoundIncludeName", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(ProvideNamed("replica", Database{}))
+		err := c.Add(ProvideNamed("replica", Database{}))
+		if err == nil || !strings.Contains(err.Error(), `[godi.Database "replica"] already exists`) {
+			t.Errorf("expected duplicate error with name, got %v", err)
+		}
+		if err = c.Add(ProvideNamed("other", Database{}), Provide(Database{})); err != nil {
+			t.Errorf("expected distinct names to coexist, got %v", err)
+		}
+		_, err = InjectNamed[Database](c, "missing")
+		if err == nil || !strings.Contains(err.Error(), `[godi.Database "missing"] not found`) {
+			t.Errorf("expected not found error with name, got %v", err)
+		}
+	})
+
+	t.Run("NamedDependencyFormAndNesting", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(
+			ProvideNamed("primary", Database{DSN: "primary"}),
+			ProvideNamed("replica", Database{DSN: "replica"}),
+		)
+		parent := &Container{}
+		parent.MustAdd(child, Build(func(d Deps2[Named[Database, primaryName], Named[Database, replicaName]]) (string, error) {
+			return d.A.Value.DSN + "," + d.B.Value.DSN, nil
+		}))
+		if v, err := Inject[string](parent); err != nil || v != "primary,replica" {
+			t.Errorf("expected primary,replica, got %v, err %v", v, err)
+		}
+		if v, err := Inject[Named[Database, replicaName]](parent); err != nil || v.Value.DSN != "replica" {
+			t.Errorf("expected replica, got %v, err %v", v, err)
+		}
+	})
+
+	t.Run("BuildErrorAndHooks", func(t *testing.T) {
+		c := &Container{}
+		count := 0
+		hook := c.HookOnce("init", func(v any) func(context.Context) {
+			return func(context.Context) { count++ }
+		})
+		c.MustAdd(
+			ProvideNamed("a", 1), ProvideNamed("b", 2),
+			BuildNamed("bad", func(_ struct{}) (int, error) { return 0, fmt.Errorf("boom") }),
+		)
+		_, err := InjectNamed[int](c, "bad")
+		if err == nil || !strings.Contains(err.Error(), `named "bad"`) || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("expected named build error, got %v", err)
+		}
+		MustInjectNamed[int](c, "a")
+		MustInjectNamed[int](c, "a")
+		MustInjectNamed[int](c, "b")
+		hook.Iterate(context.Background(), false)
+		if count != 2 {
+			t.Errorf("expected HookOnce per name, got %d", count)
+		}
+	})
+}
+
+// =============================================================================
+// Group Tests
+// =============================================================================
+
+type HealthCheck func() string
+
+func TestGroup_Collect(t *testing.T) {
+	t.Run("RegistrationOrderAcrossNestedContainers", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			infra := &Container{}
+			infra.MustAdd(
+				Provide(Database{DSN: "db"}),
+				ProvideGroup[HealthCheck](func() string { return "cache" }),
+				BuildGroup(func(db Database) (HealthCheck, error) {
+					return func() string { return db.DSN }, nil
+				}),
+			)
+			app := &Container{}
+			app.MustAdd(
+				ProvideGroup[HealthCheck](func() string { return "app" }),
+				infra,
+				ProvideGroup[HealthCheck](func() string { return "late" }),
+			)
+			checks, err := Inject[[]HealthCheck](app)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, check := range checks {
+				names = append(names, check())
+			}
+			if strings.Join(names, ",") != "cache,db,app,late" {
+				t.Fatalf("unexpected order: %v", names)
+			}
+		}
+	})
+
+	t.Run("ConsumerViaBuild", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			ProvideGroup("a"), ProvideGroup("b"),
+			Build(func(all []string) (int, error) { return len(all), nil }),
+		)
+		if n, err := Inject[int](c); err != nil || n != 2 {
+			t.Errorf("expected 2 members, got %v, err %v", n, err)
+		}
+	})
+
+	t.Run("ErrorsAndConflicts", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			ProvideGroup(1),
+			BuildGroup(func(_ struct{}) (int, error) { return 0, fmt.Errorf("boom") }),
+		)
+		if _, err := Inject[[]int](c); err == nil || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("expected member error, got %v", err)
+		}
+		if err := c.Add(Provide([]int{1})); err == nil {
+			t.Error("expected conflict between group and plain slice provider")
+		}
+		plain := (&Container{}).MustAdd(Provide([]int{1}))
+		if err := plain.Add(ProvideGroup(2)); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("expected conflict adding a member after a plain slice provider, got %v", err)
+		}
+		if err := (&Container{}).MustAdd(ProvideGroup(2)).Add(plain); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("expected conflict with a plain slice provider in a child, got %v", err)
+		}
+		if err := (&Container{}).MustAdd(ProvideGroup(2)).Add((&Container{}).MustAdd(ProvideGroup(3))); err != nil {
+			t.Errorf("expected members of nested containers to join, got %v", err)
+		}
+		if _, err := Inject[[]string](c); err == nil {
+			t.Error("expected not found for empty group")
+		}
+		c2 := &Container{}
+		c2.MustAdd(BuildGroup(func(all []bool) (bool, error) { return true, nil }))
+		if _, err := Inject[[]bool](c2); err == nil || !strings.Contains(err.Error(), "circular dependency") {
+			t.Errorf("expected circular dependency error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Map Binding Tests
+// =============================================================================
+
+type Codec interface{ Name() string }
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+type xmlCodec struct{ prefix string }
+
+func (x xmlCodec) Name() string { return x.prefix + "xml" }
+
+func TestMap_Bindings(t *testing.T) {
+	t.Run("CollectAcrossNestedContainers", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(
+			Provide(Config{AppName: "app-"}),
+			BuildMap("xml", func(cfg Config) (Codec, error) { return xmlCodec{prefix: cfg.AppName}, nil }),
+		)
+		parent := &Container{}
+		parent.MustAdd(ProvideMap[Codec]("json", jsonCodec{}), child)
+		codecs, err := Inject[map[string]Codec](parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(codecs) != 2 || codecs["json"].Name() != "json" || codecs["xml"].Name() != "app-xml" {
+			t.Errorf("unexpected codecs: %v", codecs)
+		}
+		if _, err = Inject[map[string]Codec](child); err != nil {
+			t.Errorf("expected child to inject its own entries, got %v", err)
+		}
+	})
+
+	t.Run("ConflictingKeysNameBothRegistrations", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(ProvideMap[Codec]("json", jsonCodec{}))
+		parent := &Container{}
+		parent.MustAdd(child)
+		err := parent.Add(ProvideMap[Codec]("json", jsonCodec{}))
+		if err == nil {
+			t.Fatal("expected conflicting key error")
+		}
+		if strings.Count(err.Error(), "di_test.go:") != 2 || !strings.Contains(err.Error(), `"json"`) {
+			t.Errorf("expected both registrations in error, got %v", err)
+		}
+		if err = parent.Add(ProvideMap[Codec]("xml", xmlCodec{}), ProvideMap[string]("json", "other type")); err != nil {
+			t.Errorf("expected distinct keys and types to coexist, got %v", err)
+		}
+	})
+
+	t.Run("EntryError", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(BuildMap("bad", func(_ struct{}) (int, error) { return 0, fmt.Errorf("boom") }))
+		if _, err := Inject[map[string]int](c); err == nil || !strings.Contains(err.Error(), `map entry "bad"`) {
+			t.Errorf("expected entry error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Interface Binding Tests
+// =============================================================================
+
+type UserRepository interface{ Find() string }
+type Pinger interface{ Ping() error }
+type mysqlRepo struct{ dsn string }
+
+func (r *mysqlRepo) Find() string { return r.dsn }
+func (r *mysqlRepo) Ping() error  { return nil }
+
+func TestBind_Interfaces(t *testing.T) {
+	t.Run("SharedSingletonAndHooks", func(t *testing.T) {
+		c := &Container{}
+		builds, hooks := 0, 0
+		hook := c.HookOnce("init", func(v any) func(context.Context) {
+			return func(context.Context) { hooks++ }
+		})
+		c.MustAdd(
+			Provide(Database{DSN: "mysql://localhost"}),
+			Bind[Pinger, *mysqlRepo](Bind[UserRepository, *mysqlRepo](Build(func(db Database) (*mysqlRepo, error) {
+				builds++
+				return &mysqlRepo{dsn: db.DSN}, nil
+			}))),
+		)
+		repo := MustInject[*mysqlRepo](c)
+		users := MustInject[UserRepository](c)
+		pinger := MustInject[Pinger](c)
+		if users != UserRepository(repo) || pinger != Pinger(repo) || users.Find() != "mysql://localhost" {
+			t.Errorf("expected the same instance behind all types")
+		}
+		hook.Iterate(context.Background(), false)
+		if builds != 1 || hooks != 2 {
+			t.Errorf("expected 1 build and hooks for Database and *mysqlRepo only, got %d builds, %d hooks", builds, hooks)
+		}
+	})
+
+	t.Run("NestedAndBuildDependency", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{dsn: "child"})))
+		parent := &Container{}
+		parent.MustAdd(child, Build(func(r UserRepository) (string, error) { return r.Find(), nil }))
+		if v, err := Inject[string](parent); err != nil || v != "child" {
+			t.Errorf("expected child, got %v, err %v", v, err)
+		}
+	})
+
+	t.Run("ConflictsWithRealProviders", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{})))
+		if err := c.Add(Provide[UserRepository](&mysqlRepo{})); err == nil {
+			t.Error("expected conflict adding a provider for a bound interface")
+		}
+		c2 := &Container{}
+		c2.MustAdd(Provide[UserRepository](&mysqlRepo{}))
+		err := c2.Add(Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{})))
+		if err == nil || !strings.Contains(err.Error(), "UserRepository") {
+			t.Errorf("expected conflict naming the interface, got %v", err)
+		}
+		child := &Container{}
+		child.MustAdd(Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{})))
+		if err = c2.Add(child); err == nil {
+			t.Error("expected conflict adding a child container binding the interface")
+		}
+	})
+
+	t.Run("NotImplementedAndMismatch", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(Bind[UserRepository, Database](Provide(Database{})))
+		if _, err := Inject[UserRepository](c); err == nil || !strings.Contains(err.Error(), "does not implement") {
+			t.Errorf("expected does not implement error, got %v", err)
+		}
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("expected panic for provider of another type")
+			}
+		}()
+		Bind[UserRepository, *mysqlRepo](Provide(Database{}))
+	})
+}
+
+// =============================================================================
+// Decorator Tests
+// =============================================================================
+
+func TestDecorate_Providers(t *testing.T) {
+	t.Run("StackedInOrderAndOncePerSingleton", func(t *testing.T) {
+		c := &Container{}
+		calls := 0
+		c.MustAdd(
+			Build(func(_ struct{}) (*Service, error) { return &Service{Name: "svc"}, nil }),
+			Decorate(func(s *Service) (*Service, error) { calls++; return &Service{Name: s.Name + "+log"}, nil }),
+			Decorate(func(s *Service) (*Service, error) { return &Service{Name: s.Name + "+metrics"}, nil }),
+		)
+		a, b := MustInject[*Service](c), MustInject[*Service](c)
+		if a != b || a.Name != "svc+log+metrics" || calls != 1 {
+			t.Errorf("unexpected decoration: %q, same=%v, calls=%d", a.Name, a == b, calls)
+		}
+	})
+
+	t.Run("AcrossNestedContainers", func(t *testing.T) {
+		module := &Container{}
+		module.MustAdd(Provide(Config{AppName: "app"}))
+		parent := &Container{}
+		parent.MustAdd(module, Decorate(func(cfg Config) (Config, error) {
+			cfg.AppName += "-decorated"
+			return cfg, nil
+		}))
+		if cfg := MustInject[Config](parent); cfg.AppName != "app-decorated" {
+			t.Errorf("expected decorated config, got %q", cfg.AppName)
+		}
+	})
+
+	t.Run("FactoryDecoratedEveryTime", func(t *testing.T) {
+		c := &Container{}
+		n := 0
+		c.MustAdd(
+			Factory(func(_ struct{}) (int, error) { n++; return n, nil }),
+			Decorate(func(i int) (int, error) { return i * 10, nil }),
+		)
+		if a, b := MustInject[int](c), MustInject[int](c); a != 10 || b != 20 {
+			t.Errorf("expected 10 and 20, got %d and %d", a, b)
+		}
+	})
+
+	t.Run("DecoratorErrorVisible", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Provide("value"),
+			Decorate(func(s string) (string, error) { return s, fmt.Errorf("boom") }),
+		)
+		_, err := Inject[string](c)
+		if err == nil || !strings.Contains(err.Error(), "decorate [string] (di_test.go:") || !strings.Contains(err.Error(), "boom") {
+			t.Errorf("expected decorator error with location, got %v", err)
+		}
+		if err = c.Inject(new(func(string) (string, error))); err == nil {
+			t.Error("expected decorators not to be injectable")
+		}
+	})
+}
+
+// =============================================================================
+// Override and Overlay Tests
+// =============================================================================
+
+type mockRepo struct{}
+
+func (mockRepo) Find() string { return "mock" }
+
+func TestOverride_Overlay(t *testing.T) {
+	newBase := func() (*Container, *int) {
+		hooks := 0
+		infra := &Container{}
+		infra.MustAdd(
+			Provide(Database{DSN: "mysql://prod"}),
+			Bind[UserRepository, *mysqlRepo](Build(func(db Database) (*mysqlRepo, error) { return &mysqlRepo{dsn: db.DSN}, nil })),
+		)
+		base := &Container{}
+		base.HookOnce("init", func(v any) func(context.Context) { hooks++; return nil })
+		base.MustAdd(infra, Build(func(r UserRepository) (*Service, error) { return &Service{Name: r.Find()}, nil }))
+		return base, &hooks
+	}
+
+	t.Run("OverlayReplacesTypeInFrozenChild", func(t *testing.T) {
+		base, _ := newBase()
+		before := MustInject[*Service](base)
+		overlay := base.Overlay()
+		overlay.MustOverride(Provide(Database{DSN: "sqlite://memory"}))
+		if svc := MustInject[*Service](overlay); svc.Name != "sqlite://memory" || svc == before {
+			t.Errorf("expected overlay service built with replacement, got %q", svc.Name)
+		}
+		if svc := MustInject[*Service](base); svc != before || svc.Name != "mysql://prod" {
+			t.Errorf("expected base untouched, got %q", svc.Name)
+		}
+	})
+
+	t.Run("OverlayBeforeBaseAndHooksUntouched", func(t *testing.T) {
+		base, hooks := newBase()
+		overlay := base.Overlay()
+		overlay.MustOverride(Provide[UserRepository](mockRepo{}))
+		if svc := MustInject[*Service](overlay); svc.Name != "mock" {
+			t.Errorf("expected mock repository, got %q", svc.Name)
+		}
+		if *hooks != 0 {
+			t.Errorf("expected base hooks not fired through overlay, got %d", *hooks)
+		}
+		if svc := MustInject[*Service](base); svc.Name != "mysql://prod" {
+			t.Errorf("expected base built with real repository, got %q", svc.Name)
+		}
+		if *hooks == 0 {
+			t.Error("expected base hooks fired by base injection")
+		}
+	})
+
+	t.Run("OverrideAppliesToBoundInterface", func(t *testing.T) {
+		base, _ := newBase()
+		real := MustInject[UserRepository](base)
+		overlay := base.Overlay()
+		overlay.MustOverride(Provide(&mysqlRepo{dsn: "mock"}))
+		if repo := MustInject[*mysqlRepo](overlay); repo.dsn != "mock" {
+			t.Errorf("expected mock implementation, got %q", repo.dsn)
+		}
+		if repo := MustInject[UserRepository](overlay); repo.Find() != "mock" {
+			t.Errorf("expected the interface to resolve the override, got %q", repo.Find())
+		}
+		if svc := MustInject[*Service](overlay); svc.Name != "mock" {
+			t.Errorf("expected overlay service built with the override, got %q", svc.Name)
+		}
+		if repo := MustInject[UserRepository](base); repo != real {
+			t.Error("expected base untouched")
+		}
+	})
+
+	t.Run("OverrideOnContainer", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(Provide(Config{AppName: "real"}))
+		c.MustOverride(Provide(Config{AppName: "test"}))
+		if cfg := MustInject[Config](c); cfg.AppName != "test" {
+			t.Errorf("expected replaced config, got %q", cfg.AppName)
+		}
+		if err := c.Add(Provide(Config{})); err == nil {
+			t.Error("expected duplicate error after override")
+		}
+		child := &Container{}
+		(&Container{}).MustAdd(child)
+		if err := child.Override(Provide(Config{})); err == nil || !strings.Contains(err.Error(), "frozen") {
+			t.Errorf("expected frozen error, got %v", err)
+		}
+		if err := c.Override(child); err == nil {
+			t.Error("expected error overriding with a container")
+		}
+	})
+}
+
+// =============================================================================
+// Optional Injection Tests
+// =============================================================================
+
+func TestOptional_Injection(t *testing.T) {
+	t.Run("NotRegisteredVersusFailed", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(Build(func(_ struct{}) (Database, error) { return Database{}, fmt.Errorf("dial failed") }))
+		if _, ok, err := InjectOptional[Config](c); ok || err != nil {
+			t.Errorf("expected missing config without error, got ok=%v err=%v", ok, err)
+		}
+		if _, ok, err := InjectOptional[Database](c); !ok || err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("expected registered database with build error, got ok=%v err=%v", ok, err)
+		}
+		if _, err := Inject[Config](c); !errors.Is(err, ErrNotFound) || err.Error() != "provider [godi.Config] not found" {
+			t.Errorf("expected ErrNotFound with readable message, got %v", err)
+		}
+	})
+
+	t.Run("NestedAndDependencyForm", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(Provide(Config{AppName: "app"}))
+		c := &Container{}
+		c.MustAdd(child, Build(func(d Deps2[Optional[Config], Optional[Database]]) (Service, error) {
+			if !d.A.Ok || d.B.Ok {
+				return Service{}, fmt.Errorf("unexpected optional state %v %v", d.A.Ok, d.B.Ok)
+			}
+			return Service{Cfg: d.A.Value}, nil
+		}))
+		if svc, err := Inject[Service](c); err != nil || svc.Cfg.AppName != "app" {
+			t.Errorf("expected degraded service, got %+v, err %v", svc, err)
+		}
+	})
+
+	t.Run("CircularStillReported", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(s Optional[string]) (int, error) { return len(s.Value), nil }),
+			Build(func(i int) (string, error) { return "", nil }),
+		)
+		if _, err := Inject[int](c); err == nil || !strings.Contains(err.Error(), "circular dependency") {
+			t.Errorf("expected circular dependency error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Lazy Injection Tests
+// =============================================================================
+
+type lazyA struct{ b Lazy[*lazyB] }
+
+type lazyB struct{ a Lazy[*lazyA] }
+
+func TestLazy_Injection(t *testing.T) {
+	t.Run("MutualReferences", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(b Lazy[*lazyB]) (*lazyA, error) { return &lazyA{b: b}, nil }),
+			Build(func(a Lazy[*lazyA]) (*lazyB, error) { return &lazyB{a: a}, nil }),
+		)
+		a, err := Inject[*lazyA](c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b, err := a.b.Get()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if back, err := b.a.Get(); err != nil || back != a {
+			t.Errorf("expected the shared singleton back, got %p, err %v", back, err)
+		}
+	})
+
+	t.Run("DeferredUntilGet", func(t *testing.T) {
+		var built int32
+		c := &Container{}
+		c.MustAdd(
+			Build(func(_ struct{}) (*Database, error) { atomic.AddInt32(&built, 1); return &Database{DSN: "lazy"}, nil }),
+			Build(func(db Lazy[*Database]) (Service, error) { return Service{Name: "svc"}, nil }),
+		)
+		svc, err := Inject[Service](c)
+		if err != nil || svc.Name != "svc" {
+			t.Fatalf("unexpected result %+v, err %v", svc, err)
+		}
+		if atomic.LoadInt32(&built) != 0 {
+			t.Errorf("expected database not to be built before Get")
+		}
+		h := MustInject[Lazy[*Database]](c)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if db, err := h.Get(); err != nil || db.DSN != "lazy" {
+					t.Errorf("unexpected result %+v, err %v", db, err)
+				}
+			}()
+		}
+		wg.Wait()
+		if n := atomic.LoadInt32(&built); n != 1 {
+			t.Errorf("expected a single construction, got %d", n)
+		}
+	})
+
+	t.Run("GetDuringConstruction", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(b Lazy[*lazyB]) (*lazyA, error) { _, err := b.Get(); return &lazyA{b: b}, err }),
+			Build(func(a *lazyA) (*lazyB, error) { return &lazyB{}, nil }),
+		)
+		if _, err := Inject[*lazyA](c); err == nil || !strings.Contains(err.Error(), "circular dependency") {
+			t.Errorf("expected circular dependency error, got %v", err)
+		}
+	})
+
+	t.Run("ResolvesAgainstLiveContainer", func(t *testing.T) {
+		c := &Container{}
+		var handle Lazy[Config]
+		c.MustAdd(Build(func(l Lazy[Config]) (Service, error) { handle = l; return Service{}, nil }))
+		if _, err := Inject[Service](c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := handle.Get()
+		if err == nil || err.Error() != "provider [godi.Config] not found" || len(ResolutionPath(err)) != 1 {
+			t.Errorf("expected not found without the finished chain, got %v", err)
+		}
+		c.MustAdd(Provide(Config{AppName: "late"}))
+		if cfg, err := handle.Get(); err != nil || cfg.AppName != "late" {
+			t.Errorf("expected provider added later, got %+v, err %v", cfg, err)
+		}
+	})
+
+	t.Run("ResolvesThroughScope", func(t *testing.T) {
+		var n int32
+		c := (&Container{}).MustAdd(
+			Scoped(func(_ struct{}) (*Database, error) { return &Database{DSN: fmt.Sprint(atomic.AddInt32(&n, 1))}, nil }),
+		)
+		scope := c.NewScope()
+		h := MustInject[Lazy[*Database]](scope)
+		db, err := h.Get()
+		if err != nil || db != MustInject[*Database](scope) {
+			t.Errorf("expected the scoped instance, got %+v, err %v", db, err)
+		}
+	})
+
+	t.Run("NotInjected", func(t *testing.T) {
+		var l Lazy[Config]
+		if _, err := l.Get(); err == nil {
+			t.Error("expected error for zero handle")
+		}
+	})
+}
+
+// =============================================================================
+// Context-aware Injection Tests
+// =============================================================================
+
+type ctxKey struct{}
+
+func TestContext_Injection(t *testing.T) {
+	t.Run("FlowsThroughChain", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			BuildCtx(func(ctx context.Context, _ struct{}) (Config, error) {
+				return Config{AppName: fmt.Sprint(ctx.Value(ctxKey{}))}, nil
+			}),
+			Build(func(c *Container) (Database, error) {
+				if c.Context().Value(ctxKey{}) != "req" {
+					return Database{}, fmt.Errorf("context not propagated")
+				}
+				return Database{DSN: MustInject[Config](c).AppName}, nil
+			}),
+		)
+		ctx := context.WithValue(context.Background(), ctxKey{}, "req")
+		if db, err := InjectCtx[Database](ctx, c); err != nil || db.DSN != "req" {
+			t.Errorf("expected context value through the chain, got %+v, err %v", db, err)
+		}
+	})
+
+	t.Run("CanceledNotCached", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(BuildCtx(func(ctx context.Context, _ struct{}) (Database, error) {
+			if err := ctx.Err(); err != nil {
+				return Database{}, err
+			}
+			return Database{DSN: "up"}, nil
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := InjectCtx[Database](ctx, c)
+		var canceled *CanceledError
+		if !errors.As(err, &canceled) || !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected CanceledError, got %v", err)
+		}
+		if db, err := Inject[Database](c); err != nil || db.DSN != "up" {
+			t.Errorf("expected a fresh build after cancellation, got %+v, err %v", db, err)
+		}
+	})
+
+	t.Run("WaiterGivesUp", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		c := &Container{}
+		c.MustAdd(Build(func(_ struct{}) (Database, error) {
+			close(started)
+			<-release
+			return Database{DSN: "slow"}, nil
+		}))
+		done := make(chan error)
+		go func() { _, err := Inject[Database](c); done <- err }()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := InjectCtx[Database](ctx, c); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected waiter to give up, got %v", err)
+		}
+		close(release)
+		if err := <-done; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if db, err := InjectCtx[Database](ctx, c); err == nil || db.DSN != "" {
+			t.Errorf("expected expired context to stop the injection, got %+v", db)
+		}
+		if db, err := Inject[Database](c); err != nil || db.DSN != "slow" {
+			t.Errorf("expected the singleton, got %+v, err %v", db, err)
+		}
+	})
+}
+
+func TestContext_CanceledNamesRequestedType(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	c := (&Container{}).MustAdd(
+		Bind[UserRepository, *mysqlRepo](Build(func(_ struct{}) (*mysqlRepo, error) {
+			close(started)
+			<-release
+			return &mysqlRepo{}, nil
+		})),
+		Build(func(repo UserRepository) (Service, error) { return Service{}, nil }),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := InjectCtx[UserRepository](ctx, c)
+	if err == nil || err.Error() != "inject [godi.UserRepository] canceled: context canceled" {
+		t.Errorf("expected the requested interface, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() { _, err := Inject[UserRepository](c); done <- err }()
+	<-started
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = InjectCtx[Service](ctx, c)
+	var canceled *CanceledError
+	if !errors.As(err, &canceled) || canceled.Type != "godi.UserRepository" || fmt.Sprint(canceled.Path) != "[godi.Service godi.UserRepository]" {
+		t.Errorf("expected the waiting injection to be named with its path, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// =============================================================================
+// Build Retry Tests
+// =============================================================================
+
+func TestBuild_Retry(t *testing.T) {
+	flaky := func(failures int32, calls *int32) func(struct{}) (Database, error) {
+		return func(struct{}) (Database, error) {
+			if n := atomic.AddInt32(calls, 1); n <= failures {
+				return Database{}, fmt.Errorf("attempt %d: not up yet", n)
+			}
+			return Database{DSN: "up"}, nil
+		}
+	}
+
+	t.Run("ErrorCachedByDefault", func(t *testing.T) {
+		var calls int32
+		c := (&Container{}).MustAdd(Build(flaky(1, &calls)))
+		for i := 0; i < 3; i++ {
+			if _, err := Inject[Database](c); err == nil {
+				t.Fatal("expected cached error")
+			}
+		}
+		if calls != 1 {
+			t.Errorf("expected a single construction, got %d", calls)
+		}
+	})
+
+	t.Run("RetriedUntilSuccess", func(t *testing.T) {
+		var calls int32
+		c := (&Container{}).MustAdd(Build(flaky(2, &calls), Retry(0)))
+		for i := 0; i < 2; i++ {
+			if _, err := Inject[Database](c); err == nil {
+				t.Fatal("expected construction error")
+			}
+		}
+		for i := 0; i < 2; i++ {
+			if db, err := Inject[Database](c); err != nil || db.DSN != "up" {
+				t.Fatalf("expected success, got %+v, err %v", db, err)
+			}
+		}
+		if calls != 3 {
+			t.Errorf("expected the success to stay a singleton, got %d constructions", calls)
+		}
+	})
+
+	t.Run("AttemptLimit", func(t *testing.T) {
+		var calls int32
+		c := (&Container{}).MustAdd(BuildCtx(func(_ context.Context, v struct{}) (Database, error) { return flaky(5, &calls)(v) }, Retry(2)))
+		for i := 0; i < 4; i++ {
+			if _, err := Inject[Database](c); err == nil || !strings.Contains(err.Error(), "attempt") {
+				t.Fatalf("expected construction error, got %v", err)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 constructions, got %d", calls)
+		}
+	})
+
+	t.Run("Backoff", func(t *testing.T) {
+		var calls int32
+		c := (&Container{}).MustAdd(Build(flaky(1, &calls), Retry(0), Backoff(20*time.Millisecond)))
+		for i := 0; i < 2; i++ {
+			if _, err := Inject[Database](c); err == nil || !strings.Contains(err.Error(), "attempt 1") {
+				t.Fatalf("expected first error during backoff, got %v", err)
+			}
+		}
+		time.Sleep(30 * time.Millisecond)
+		if _, err := Inject[Database](c); err != nil || calls != 2 {
+			t.Errorf("expected retry after backoff, got err %v after %d constructions", err, calls)
+		}
+	})
+
+	t.Run("NamedGroupAndMap", func(t *testing.T) {
+		var named, member, entry int32
+		c := (&Container{}).MustAdd(
+			BuildNamed("replica", flaky(1, &named), Retry(0)),
+			BuildGroup(flaky(1, &member), Retry(0)),
+			BuildMap("main", flaky(1, &entry), Retry(0)),
+		)
+		for i := 0; i < 2; i++ {
+			_, err1 := InjectNamed[Database](c, "replica")
+			_, err2 := Inject[[]Database](c)
+			_, err3 := Inject[map[string]Database](c)
+			if failed := err1 != nil || err2 != nil || err3 != nil; failed != (i == 0) {
+				t.Fatalf("attempt %d: got %v, %v, %v", i, err1, err2, err3)
+			}
+		}
+		if named != 2 || member != 2 || entry != 2 {
+			t.Errorf("expected one retry each, got %d, %d, %d", named, member, entry)
+		}
+	})
+
+	t.Run("Scoped", func(t *testing.T) {
+		var calls int32
+		s := (&Container{}).MustAdd(Scoped(flaky(1, &calls), Retry(0))).NewScope()
+		if _, err := Inject[Database](s); err == nil {
+			t.Fatal("expected construction error")
+		}
+		if db, err := Inject[Database](s); err != nil || db.DSN != "up" || calls != 2 {
+			t.Errorf("expected a retry within the scope, got %+v, err %v after %d constructions", db, err, calls)
+		}
+	})
+
+	t.Run("SingleInFlight", func(t *testing.T) {
+		var calls int32
+		c := (&Container{}).MustAdd(Build(func(struct{}) (Database, error) {
+			time.Sleep(5 * time.Millisecond)
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return Database{}, fmt.Errorf("not up yet")
+			}
+			return Database{DSN: "up"}, nil
+		}, Retry(0)))
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() { defer wg.Done(); _, _ = Inject[Database](c) }()
+		}
+		wg.Wait()
+		if db, err := Inject[Database](c); err != nil || db.DSN != "up" {
+			t.Errorf("expected success, got %+v, err %v", db, err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 2 {
+			t.Errorf("expected 2 constructions, got %d", n)
+		}
+	})
+}
+
+// =============================================================================
+// Warmup Tests
+// =============================================================================
+
+func TestContainer_Warmup(t *testing.T) {
+	t.Run("BuildsEverySingleton", func(t *testing.T) {
+		var built int32
+		count := func() { atomic.AddInt32(&built, 1) }
+		child := &Container{}
+		child.MustAdd(Build(func(_ struct{}) (Config, error) { count(); return Config{AppName: "app"}, nil }))
+		c := &Container{}
+		c.MustAdd(
+			child,
+			Provide("value"),
+			Factory(func(_ struct{}) (int, error) { t.Error("factory must not be warmed"); return 0, nil }),
+			Build(func(cfg Config) (Database, error) { count(); return Database{DSN: cfg.AppName}, nil }),
+			BuildNamed("replica", func(_ struct{}) (Database, error) { count(); return Database{}, nil }),
+			BuildGroup(func(_ struct{}) (HealthCheck, error) { count(); return func() string { return "db" }, nil }),
+			Bind[Pinger, *mysqlRepo](Build(func(_ struct{}) (*mysqlRepo, error) { count(); return &mysqlRepo{}, nil })),
+		)
+		if err := c.Warmup(context.Background(), 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if n := atomic.LoadInt32(&built); n != 5 {
+			t.Errorf("expected 5 constructions, got %d", n)
+		}
+		if _, err := Inject[Database](c); err != nil || atomic.LoadInt32(&built) != 5 {
+			t.Errorf("expected warmed singleton, got err %v after %d constructions", err, built)
+		}
+	})
+
+	t.Run("ReportsAllFailures", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(_ struct{}) (Config, error) { return Config{}, fmt.Errorf("config broken") }),
+			Build(func(_ struct{}) (Database, error) { return Database{}, fmt.Errorf("database broken") }),
+			Build(func(s string) (Service, error) { return Service{}, nil }),
+		)
+		err := c.Warmup(context.Background(), 0)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("expected 3 aggregated errors, got %v", err)
+		}
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected missing dependency among failures, got %v", err)
+		}
+		for _, msg := range []string{"config broken", "database broken"} {
+			if !strings.Contains(err.Error(), msg) {
+				t.Errorf("expected %q in %v", msg, err)
+			}
+		}
+	})
+
+	t.Run("ParallelWithLimit", func(t *testing.T) {
+		var running, peak int32
+		// The first constructions wait until 3 of them run together
+		together, release := make(chan struct{}), sync.Once{}
+		slow := func(_ struct{}) (int32, error) {
+			n := atomic.AddInt32(&running, 1)
+			for p := atomic.LoadInt32(&peak); n > p && !atomic.CompareAndSwapInt32(&peak, p, n); p = atomic.LoadInt32(&peak) {
+			}
+			if n == 3 {
+				release.Do(func() { close(together) })
+			}
+			select {
+			case <-together:
+			case <-time.After(5 * time.Second):
+				t.Error("expected 3 constructions running together")
+			}
+			atomic.AddInt32(&running, -1)
+			return n, nil
+		}
+		c := &Container{}
+		for i := 0; i < 6; i++ {
+			c.MustAdd(BuildNamed(strconv.Itoa(i), slow))
+		}
+		if err := c.Warmup(context.Background(), 3); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p := atomic.LoadInt32(&peak); p != 3 {
+			t.Errorf("expected parallel constructions limited to 3, got peak %d", p)
+		}
+	})
+}
+
+// =============================================================================
+// Validate Tests
+// =============================================================================
+
+func TestContainer_Validate(t *testing.T) {
+	fail := func(string) (int, error) { t.Error("validate must not construct anything"); return 0, nil }
+
+	t.Run("Valid", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(Provide(Config{}), ProvideNamed("replica", Database{}))
+		c := &Container{}
+		c.MustAdd(
+			child,
+			Provide("value"),
+			Build(fail),
+			Build2(func(cfg Config, i int) (Service, error) { return Service{}, nil }),
+			Build(func(d Named[Database, replicaName]) (*Database, error) { return &d.Value, nil }),
+			Build(func(o Optional[float64]) (bool, error) { return o.Ok, nil }),
+			Build(func(c *Container) (uint, error) { return 0, nil }, Requires[Service]()),
+		)
+		if err := c.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("MissingDependencies", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(fail),
+			Build2(func(cfg Config, db Database) (Service, error) { return Service{}, nil }),
+			Build(func(c *Container) (uint, error) { return 0, nil }, Requires[*Database]()),
+			Provide(Config{}),
+		)
+		err := c.Validate()
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 3 || !errors.Is(err, ErrNotFound) {
+			t.Fatalf("expected 3 missing dependencies, got %v", err)
+		}
+		paths := make(map[string]string)
+		for _, e := range errs {
+			var cerr *ContainerError
+			if errors.As(e, &cerr) && cerr.Err == ErrNotFound && cerr.Container == c {
+				paths[cerr.Type] = fmt.Sprint(cerr.Path)
+			}
+		}
+		if len(paths) != 3 || paths["string"] != "[int string]" {
+			t.Errorf("expected ContainerErrors like at injection time, got %v", paths)
+		}
+		for _, msg := range []string{
+			"provider [string] not found (resolving int -> string)",
+			"provider [godi.Database] not found (resolving godi.Service -> godi.Database)",
+			"provider [*godi.Database] not found (resolving uint -> *godi.Database)",
+		} {
+			if !strings.Contains(err.Error(), msg) {
+				t.Errorf("expected %q in %v", msg, err)
+			}
+		}
+	})
+
+	t.Run("RequiresOnEveryBuilder", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Factory(func(c *Container) (int, error) { return 0, nil }, Requires[Config]()),
+			Scoped(func(c *Container) (uint, error) { return 0, nil }, Requires[*Database]()),
+			BuildNamed("n", func(c *Container) (string, error) { return "", nil }, Requires[float64]()),
+			BuildGroup(func(c *Container) (bool, error) { return false, nil }, Requires[int8]()),
+			BuildMap("k", func(c *Container) (byte, error) { return 0, nil }, Requires[int16]()),
+		)
+		err := c.Validate()
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 5 {
+			t.Fatalf("expected 5 missing dependencies, got %v", err)
+		}
+		for _, missing := range []string{"godi.Config", "*godi.Database", "float64", "int8", "int16"} {
+			if !strings.Contains(err.Error(), "provider ["+missing+"] not found") {
+				t.Errorf("expected %s reported in %v", missing, err)
+			}
+		}
+	})
+
+	t.Run("UnmatchedDecorators", func(t *testing.T) {
+		child := &Container{}
+		child.MustAdd(
+			Bind[UserRepository, *mysqlRepo](Build(func(struct{}) (*mysqlRepo, error) { return &mysqlRepo{}, nil })),
+			ProvideGroup(1),
+			ProvideNamed("replica", Database{}),
+		)
+		c := &Container{}
+		c.MustAdd(
+			child,
+			Decorate(func(r *mysqlRepo) (*mysqlRepo, error) { return r, nil }),
+			Decorate(func(i int) (int, error) { return i, nil }),
+			Decorate(func(db Database) (Database, error) { return db, nil }),
+		)
+		if err := c.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		c.MustAdd(Decorate(func(r UserRepository) (UserRepository, error) { return r, nil }))
+		err := c.Validate()
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) || cerr.Err != ErrNotFound || cerr.Type != "godi.UserRepository" {
+			t.Fatalf("expected the interface decorator reported, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "(resolving decorator at di_test.go:") {
+			t.Errorf("expected the decorator location, got %v", err)
+		}
+	})
+
+	t.Run("Cycles", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(s string) (int, error) { return 0, nil }),
+			Build(func(d Deps2[int, Config]) (string, error) { return "", nil }),
+			Provide(Config{}),
+			Build(func(b *lazyB) (*lazyA, error) { return nil, nil }),
+			Build(func(a Lazy[*lazyA]) (*lazyB, error) { return nil, nil }),
+		)
+		err := c.Validate()
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 1 {
+			t.Fatalf("expected a single cycle, got %v", err)
+		}
+		if msg := "circular dependency for [int]: int -> string -> int"; err.Error() != msg {
+			t.Errorf("expected %q, got %q", msg, err)
+		}
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) || cerr.Err != ErrCircular || fmt.Sprint(cerr.Cycle()) != "[int string int]" || cerr.Container != c {
+			t.Errorf("expected a ContainerError like at injection time, got %+v", cerr)
+		}
+	})
+}
+
+// =============================================================================
+// Graph Export Tests
+// =============================================================================
+
+func TestContainer_Graph(t *testing.T) {
+	newContainer := func() *Container {
+		child := &Container{}
+		child.MustAdd(Provide(Config{AppName: "app"}))
+		c := &Container{}
+		c.MustAdd(
+			child,
+			Build(func(cfg Config) (Database, error) { return Database{DSN: cfg.AppName}, nil }),
+			Build(func(c *Container) (Service, error) { return Service{DB: MustInject[Database](c)}, nil }),
+		)
+		return c
+	}
+
+	t.Run("NodesAndEdges", func(t *testing.T) {
+		c := newContainer()
+		g := c.Graph()
+		want := []GraphNode{
+			{ID: "godi.Config", Kind: KindValue, Owner: "root/0"},
+			{ID: "godi.Database", Kind: KindBuild, Owner: "root"},
+			{ID: "godi.Service", Kind: KindBuild, Owner: "root"},
+		}
+		if fmt.Sprint(g.Nodes) != fmt.Sprint(want) {
+			t.Errorf("got nodes %v, want %v", g.Nodes, want)
+		}
+		if len(g.Edges) != 1 || g.Edges[0] != (GraphEdge{From: "godi.Database", To: "godi.Config", Declared: true}) {
+			t.Errorf("expected the declared edge only, got %v", g.Edges)
+		}
+
+		MustInject[Service](c)
+		g = c.Graph()
+		wantEdges := []GraphEdge{
+			{From: "godi.Database", To: "godi.Config", Declared: true, Observed: true},
+			{From: "godi.Service", To: "godi.Database", Observed: true},
+		}
+		if fmt.Sprint(g.Edges) != fmt.Sprint(wantEdges) {
+			t.Errorf("got edges %v, want %v", g.Edges, wantEdges)
+		}
+	})
+
+	t.Run("Encoders", func(t *testing.T) {
+		c := newContainer()
+		MustInject[Service](c)
+		g := c.Graph()
+
+		dot := g.DOT()
+		for _, s := range []string{
+			"digraph godi {",
+			`subgraph "cluster_root/0" {`,
+			`"godi.Config" [label="godi.Config\nvalue"];`,
+			`"godi.Database" -> "godi.Config";`,
+			`"godi.Service" -> "godi.Database" [style=dashed];`,
+		} {
+			if !strings.Contains(dot, s) {
+				t.Errorf("expected %q in DOT output:\n%s", s, dot)
+			}
+		}
+
+		mermaid := g.Mermaid()
+		for _, s := range []string{"flowchart LR", `n0["godi.Config (value)"]`, "n1 --> n0", "n2 -.-> n1"} {
+			if !strings.Contains(mermaid, s) {
+				t.Errorf("expected %q in Mermaid output:\n%s", s, mermaid)
+			}
+		}
+
+		data, err := g.JSON()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var decoded struct {
+			Nodes []struct{ ID, Kind, Owner string }
+		}
+		if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Nodes) != 3 || decoded.Nodes[1].Kind != "build" {
+			t.Errorf("unexpected JSON output %s, err %v", data, err)
+		}
+	})
+
+	t.Run("DecoratorsAndBindings", func(t *testing.T) {
+		c := (&Container{}).MustAdd(
+			Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{})),
+			Decorate(func(r *mysqlRepo) (*mysqlRepo, error) { return r, nil }),
+			Build(func(repo UserRepository) (Service, error) { return Service{}, nil }),
+		)
+		g := c.Graph()
+		want := []GraphNode{
+			{ID: "*godi.mysqlRepo", Kind: KindValue, Owner: "root", Aliases: []string{"godi.UserRepository"}},
+			{ID: "godi.Service", Kind: KindBuild, Owner: "root"},
+		}
+		if fmt.Sprint(g.Nodes) != fmt.Sprint(want) {
+			t.Errorf("got nodes %v, want %v", g.Nodes, want)
+		}
+		if len(g.Edges) != 1 || g.Edges[0].To != "*godi.mysqlRepo" {
+			t.Errorf("expected the dependency on the interface to reach the bound type, got %v", g.Edges)
+		}
+		for out, s := range map[string]string{
+			g.DOT():     `"*godi.mysqlRepo" [label="*godi.mysqlRepo\nvalue\nas godi.UserRepository"];`,
+			g.Mermaid(): `n0["*godi.mysqlRepo (value) as godi.UserRepository"]`,
+		} {
+			if !strings.Contains(out, s) || strings.Contains(out, "decorator") {
+				t.Errorf("expected %q without decorators in:\n%s", s, out)
+			}
+		}
+		if data, _ := g.JSON(); strings.Contains(string(data), "decorator") || !strings.Contains(string(data), `"aliases"`) {
+			t.Errorf("unexpected JSON output %s", data)
+		}
+	})
+}
+
+// =============================================================================
+// Introspection Tests
+// =============================================================================
+
+func TestContainer_Providers(t *testing.T) {
+	child := &Container{}
+	child.MustAdd(
+		Provide(Config{AppName: "app"}),
+		Factory(func(_ struct{}) (int, error) { return 1, nil }),
+	)
+	c := &Container{}
+	c.MustAdd(
+		Build(func(_ struct{}) (Database, error) { return Database{}, fmt.Errorf("dial failed") }),
+		child,
+		BuildNamed("main", func(cfg Config) (Service, error) { return Service{Cfg: cfg}, nil }),
+		ProvideGroup[HealthCheck](func() string { return "ok" }),
+		Decorate(func(db Database) (Database, error) { return db, nil }),
+		Bind[Pinger, *mysqlRepo](Bind[UserRepository, *mysqlRepo](Provide(&mysqlRepo{}))),
+	)
+	_, _ = Inject[Database](c)
+	_, _ = InjectNamed[Service](c, "main")
+
+	got := c.Providers()
+	want := []ProviderInfo{
+		{Type: "godi.Database", Kind: KindBuild, Built: true, Err: got[0].Err, Owner: "root"},
+		{Type: "*godi.Container", Kind: KindContainer, Owner: "root"},
+		{Type: "godi.Config", Kind: KindValue, Built: true, Owner: "root/0"},
+		{Type: "int", Kind: KindFactory, Owner: "root/0"},
+		{Type: `godi.Service "main"`, Kind: KindBuild, Built: true, Owner: "root"},
+		{Type: "[]godi.HealthCheck", Kind: KindValue, Owner: "root"},
+		{Type: "*godi.mysqlRepo", Kind: KindValue, Owner: "root", Aliases: []string{"godi.UserRepository", "godi.Pinger"}},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+	if got[0].Err == nil || !strings.Contains(got[0].Err.Error(), "dial failed") {
+		t.Errorf("expected the build error, got %v", got[0].Err)
+	}
+
+	var visited int
+	c.Walk(func(pi ProviderInfo) bool { visited++; return pi.Kind != KindContainer })
+	if visited != 2 {
+		t.Errorf("expected Walk to stop at the child container, visited %d", visited)
+	}
+}
+
+// =============================================================================
+// Typed Error Tests
+// =============================================================================
+
+func TestErrors_Typed(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		c := &Container{}
+		_, err := Inject[*Database](c)
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) || !errors.Is(err, ErrNotFound) || cerr.Type != "*godi.Database" || cerr.Container != c {
+			t.Errorf("unexpected error %#v", err)
+		}
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		c := (&Container{}).MustAdd(Provide(Config{}), ProvideMap("k", 1))
+		err := c.Add(Provide(Config{}))
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) || !errors.Is(err, ErrDuplicate) || cerr.Type != "godi.Config" || cerr.Container != c {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err.Error() != "provider [godi.Config] already exists" {
+			t.Errorf("unexpected message %q", err)
+		}
+		err = c.Add(ProvideMap("k", 2))
+		if !errors.As(err, &cerr) || cerr.Conflict == "" || !strings.Contains(err.Error(), "conflicts with [map[string]int \"k\"") {
+			t.Errorf("expected both registrations in %v", err)
+		}
+	})
+
+	t.Run("Frozen", func(t *testing.T) {
+		child := &Container{}
+		(&Container{}).MustAdd(child)
+		err := child.Add(Provide(1))
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) || !errors.Is(err, ErrFrozen) || cerr.Container != child {
+			t.Errorf("unexpected error %v", err)
+		}
+		if err.Error() != "container frozen: already provided as child" {
+			t.Errorf("unexpected message %q", err)
+		}
+	})
+
+	t.Run("Circular", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(s string) (int, error) { return 0, nil }),
+			Build(func(i int) (string, error) { return "", nil }),
+		)
+		_, err := Inject[int](c)
+		var cerr *ContainerError
+		if !errors.Is(err, ErrCircular) || !errors.As(err, &cerr) || cerr.Type != "int" || cerr.Container != c {
+			t.Errorf("expected ErrCircular for int, got %v", err)
+		}
+		if msg := "circular dependency for [int]: int -> string -> int"; err.Error() != msg {
+			t.Errorf("got %q, want %q", err, msg)
+		}
+	})
+
+	t.Run("Build", func(t *testing.T) {
+		cause := errors.New("dial failed")
+		c := &Container{}
+		c.MustAdd(Build(func(_ struct{}) (*Database, error) { return nil, cause }))
+		_, err := Inject[*Database](c)
+		var berr *BuildError
+		if !errors.As(err, &berr) || berr.Type != "*godi.Database" || berr.Container != c || berr.Err != cause {
+			t.Errorf("expected BuildError for *godi.Database, got %v", err)
+		}
+		if msg := "build [*godi.Database] error: dial failed"; err.Error() != msg {
+			t.Errorf("got %q, want %q", err, msg)
+		}
+	})
+}
+
+// =============================================================================
+// Resolution Path Tests
+// =============================================================================
+
+type pathRepo struct{}
+
+func TestErrors_ResolutionPath(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(r pathRepo) (*Service, error) { return &Service{}, nil }),
+			Build(func(c *Container) (pathRepo, error) { _, err := Inject[Config](c); return pathRepo{}, err }),
+		)
+		_, err := Inject[*Service](c)
+		want := []string{"*godi.Service", "godi.pathRepo", "godi.Config"}
+		if got := ResolutionPath(err); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got path %v, want %v", got, want)
+		}
+		var berr *BuildError
+		if !errors.As(err, &berr) || fmt.Sprint(berr.Path) != "[*godi.Service godi.pathRepo]" {
+			t.Errorf("expected BuildError with its path, got %v", err)
+		}
+		msg := "build [godi.pathRepo] error: provider [godi.Config] not found (resolving *godi.Service -> godi.pathRepo -> godi.Config)"
+		if err.Error() != msg {
+			t.Errorf("got %q, want %q", err, msg)
+		}
+	})
+
+	t.Run("WholeCycle", func(t *testing.T) {
+		c := &Container{}
+		c.MustAdd(
+			Build(func(cfg Config) (*Service, error) { return &Service{}, nil }),
+			Build(func(db Database) (Config, error) { return Config{}, nil }),
+			Build(func(s string) (Database, error) { return Database{}, nil }),
+			Build(func(cfg Config) (string, error) { return "", nil }),
+		)
+		_, err := Inject[*Service](c)
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("expected ContainerError, got %v", err)
+		}
+		if got := fmt.Sprint(cerr.Path); got != "[*godi.Service godi.Config godi.Database string godi.Config]" {
+			t.Errorf("unexpected path %s", got)
+		}
+		if msg := "circular dependency for [godi.Config]: godi.Config -> godi.Database -> string -> godi.Config"; err.Error() != msg {
+			t.Errorf("got %q, want %q", err, msg)
+		}
+	})
+
+	t.Run("DirectInjection", func(t *testing.T) {
+		_, err := Inject[Config](&Container{})
+		if got := ResolutionPath(err); fmt.Sprint(got) != "[godi.Config]" || err.Error() != "provider [godi.Config] not found" {
+			t.Errorf("unexpected path %v for %v", got, err)
+		}
+	})
+}
+
+// =============================================================================
+// Suggestion Tests
+// =============================================================================
+
+type Reader struct{}
+
+func TestErrors_Suggestions(t *testing.T) {
+	suggestions := func(err error) string {
+		var cerr *ContainerError
+		if !errors.As(err, &cerr) {
+			t.Fatalf("expected ContainerError, got %v", err)
+		}
+		return strings.Join(cerr.Suggestions, "; ")
+	}
+
+	t.Run("PointerValueMismatch", func(t *testing.T) {
+		child := (&Container{}).MustAdd(Provide(&Database{}))
+		c := (&Container{}).MustAdd(child)
+		_, err := Inject[Database](c)
+		if got := suggestions(err); got != "*godi.Database (pointer/value mismatch)" {
+			t.Errorf("unexpected suggestions %q", got)
+		}
+		want := "provider [godi.Database] not found; did you mean *godi.Database (pointer/value mismatch)?"
+		if err.Error() != want {
+			t.Errorf("got %q, want %q", err, want)
+		}
+		var db *Database
+		if err := c.Inject(&db); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var value Database
+		if got := suggestions(c.Inject(&value)); got != "*godi.Database (pointer/value mismatch)" {
+			t.Errorf("expected name-based suggestions without generics, got %q", got)
+		}
+	})
+
+	t.Run("OtherPackage", func(t *testing.T) {
+		c := (&Container{}).MustAdd(Provide(strings.NewReader("")))
+		_, err := Inject[Reader](c)
+		if got := suggestions(err); got != "*strings.Reader (same name, other package)" {
+			t.Errorf("unexpected suggestions %q", got)
+		}
+	})
+
+	t.Run("Interfaces", func(t *testing.T) {
+		c := (&Container{}).MustAdd(
+			Provide(&mysqlRepo{}),
+			Provide[Codec](jsonCodec{}),
+		)
+		_, err := Inject[UserRepository](c)
+		if got := suggestions(err); got != "*godi.mysqlRepo (implements godi.UserRepository)" {
+			t.Errorf("unexpected suggestions %q", got)
+		}
+		_, err = Inject[xmlCodec](c)
+		if got := suggestions(err); got != "godi.Codec (interface implemented by godi.xmlCodec)" {
+			t.Errorf("unexpected suggestions %q", got)
+		}
+	})
+
+	t.Run("NothingClose", func(t *testing.T) {
+		c := (&Container{}).MustAdd(Provide(Config{}))
+		if _, err := Inject[int](c); suggestions(err) != "" || err.Error() != "provider [int] not found" {
+			t.Errorf("expected no suggestions, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Lifecycle Tests
+// =============================================================================
+
+type lcComponent struct {
+	name    string
+	log     *[]string
+	failOn  string
+	stopErr error
+}
+
+func (l *lcComponent) Start(context.Context) error {
+	if l.failOn == "start" {
+		return errors.New("boom")
+	}
+	*l.log = append(*l.log, "start "+l.name)
+	return nil
+}
+
+func (l *lcComponent) Stop(context.Context) error {
+	*l.log = append(*l.log, "stop "+l.name)
+	return l.stopErr
+}
+
+func (l *lcComponent) Close() error {
+	*l.log = append(*l.log, "close "+l.name)
+	return nil
+}
+
+type (
+	lcDB     struct{ *lcComponent }
+	lcRepo   struct{ *lcComponent }
+	lcServer struct{ *lcComponent }
+)
+
+// lcStopper only implements Stopper.
+type lcStopper struct {
+	name string
+	log  *[]string
+}
+
+func (l lcStopper) Stop(context.Context) error {
+	*l.log = append(*l.log, "stop "+l.name)
+	return nil
+}
+
+func newLifecycle(log *[]string, fail map[string]string) *Container {
+	component := func(name string) *lcComponent {
+		return &lcComponent{name: name, log: log, failOn: fail[name]}
+	}
+	// Registered out of dependency order on purpose
+	return (&Container{}).MustAdd(
+		Build(func(c *Container) (lcServer, error) {
+			_, err := Inject[lcRepo](c)
+			return lcServer{component("server")}, err
+		}),
+		Build(func(lcDB) (lcRepo, error) { return lcRepo{component("repo")}, nil }),
+		Build(func(struct{}) (lcDB, error) { return lcDB{component("db")}, nil }),
+		Provide(&lcComponent{name: "provided", log: log}),
+	)
+}
+
+func TestContainer_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DependencyOrder", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, nil)
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "start db,start repo,start server,stop server,close server,stop repo,close repo,stop db,close db"
+		if got := strings.Join(log, ","); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("StartFailureRollsBack", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, map[string]string{"server": "start"})
+		err := c.Start(ctx)
+		if err == nil || err.Error() != "start godi.lcServer: boom" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(log, ","); got != "start db,start repo,stop repo,stop db" {
+			t.Errorf("unexpected rollback %s", got)
+		}
+	})
+
+	t.Run("StopWithoutStart", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, nil)
+		if _, err := Inject[lcRepo](c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(log, ","); got != "stop repo,close repo,stop db,close db" {
+			t.Errorf("got %s", got)
+		}
+	})
+
+	t.Run("AggregatedErrors", func(t *testing.T) {
+		var log []string
+		errStop := errors.New("stuck")
+		c := (&Container{}).MustAdd(
+			Build(func(struct{}) (lcDB, error) { return lcDB{&lcComponent{name: "db", log: &log, stopErr: errStop}}, nil }),
+			Build(func(struct{}) (lcRepo, error) {
+				return lcRepo{&lcComponent{name: "repo", log: &log, stopErr: errStop}}, nil
+			}),
+		)
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err := c.Stop(ctx)
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(err, errStop) {
+			t.Fatalf("expected both stop errors, got %v", err)
+		}
+		if got := strings.Join(log, ","); got != "start db,start repo,stop repo,close repo,stop db,close db" {
+			t.Errorf("got %s", got)
+		}
+	})
+
+	t.Run("StartTwice", func(t *testing.T) {
+		var log []string
+		c := (&Container{}).MustAdd(Build(func(struct{}) (lcDB, error) { return lcDB{&lcComponent{name: "db", log: &log}}, nil }))
+		for i := 0; i < 2; i++ {
+			if err := c.Start(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		c.MustAdd(Build(func(lcDB) (lcRepo, error) { return lcRepo{&lcComponent{name: "repo", log: &log}}, nil }))
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(log, ","); got != "start db,start repo,stop repo,close repo,stop db,close db" {
+			t.Errorf("expected each component started and stopped once, got %s", got)
+		}
+	})
+
+	t.Run("StopperOnly", func(t *testing.T) {
+		var log []string
+		c := (&Container{}).MustAdd(Build(func(struct{}) (lcStopper, error) { return lcStopper{"worker", &log}, nil }))
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(log, ","); got != "stop worker" {
+			t.Errorf("expected the stopper to be stopped, got %s", got)
+		}
+	})
+
+	t.Run("StopTwice", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, nil)
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := c.Stop(ctx); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "start db,start repo,start server,stop server,close server,stop repo,close repo,stop db,close db"
+		if got := strings.Join(log, ","); got != want {
+			t.Errorf("expected each component shut down once, got %s", got)
+		}
+	})
+
+	t.Run("RollbackThenStop", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, map[string]string{"server": "start"})
+		if err := c.Start(ctx); err == nil {
+			t.Fatal("expected start error")
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := "start db,start repo,stop repo,stop db,stop server,close server,close repo,close db"
+		if got := strings.Join(log, ","); got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("Group", func(t *testing.T) {
+		var log []string
+		c := (&Container{}).MustAdd(
+			BuildGroup(func(struct{}) (lcStopper, error) { return lcStopper{"a", &log}, nil }),
+			BuildGroup(func(struct{}) (lcStopper, error) { return lcStopper{"b", &log}, nil }),
+		)
+		if err := c.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := c.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sort.Strings(log); strings.Join(log, ",") != "stop a,stop b" {
+			t.Errorf("expected every member stopped, got %v", log)
+		}
+	})
+
+	t.Run("Overlay", func(t *testing.T) {
+		var log []string
+		n := 0
+		c := (&Container{}).MustAdd(Build(func(struct{}) (lcStopper, error) {
+			n++
+			return lcStopper{fmt.Sprint("worker", n), &log}, nil
+		}))
+		if _, err := Inject[lcStopper](c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		o := c.Overlay()
+		if err := o.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := o.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := strings.Join(log, ","); got != "stop worker2" {
+			t.Errorf("expected only the overlay copy stopped, got %s", got)
+		}
+	})
+
+	t.Run("ScopeSharesSingletons", func(t *testing.T) {
+		var log []string
+		c := newLifecycle(&log, nil)
+		s := c.NewScope()
+		if err := s.Start(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.Stop(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(log) != 0 {
+			t.Errorf("expected the scope to leave shared singletons alone, got %v", log)
+		}
+		if err := c.Stop(ctx); err != nil || len(log) != 6 {
+			t.Errorf("expected the root to shut them down, got %v %v", log, err)
+		}
+	})
+
+	t.Run("WarmupError", func(t *testing.T) {
+		c := (&Container{}).MustAdd(Build(func(Config) (lcDB, error) { return lcDB{}, nil }))
+		if err := c.Start(ctx); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Error Hook Tests
+// =============================================================================
+
+func TestHooks_IterateErr(t *testing.T) {
+	errClose := errors.New("close failed")
+	newContainer := func(log *[]string) (*Container, Callbacks) {
+		c := (&Container{}).MustAdd(
+			Provide(Config{AppName: "app"}),
+			Provide(&Database{DSN: "db"}),
+			Provide(42),
+		)
+		shutdown := c.HookOnceErr("shutdown", func(v any) func(context.Context) error {
+			return func(context.Context) error {
+				*log = append(*log, fmt.Sprintf("%T", v))
+				if _, ok := v.(int); ok {
+					return nil
+				}
+				return errClose
+			}
+		})
+		for _, ptr := range []any{new(Config), new(*Database), new(int)} {
+			if err := c.Inject(ptr); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		return c, shutdown
+	}
+
+	t.Run("CollectsAllErrors", func(t *testing.T) {
+		var log []string
+		_, shutdown := newContainer(&log)
+		err := shutdown.IterateErr(context.Background(), true)
+		if got := strings.Join(log, ","); got != "int,*godi.Database,godi.Config" {
+			t.Errorf("expected every callback to run in reverse, got %s", got)
+		}
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 2 || !errors.Is(err, errClose) {
+			t.Fatalf("expected 2 errors, got %v", err)
+		}
+		var herr *HookError
+		if !errors.As(errs[0], &herr) || herr.Hook != "shutdown" || herr.Type != "*godi.Database" || herr.Instance.(*Database).DSN != "db" {
+			t.Errorf("unexpected hook error %+v", herr)
+		}
+		if want := "hook shutdown [godi.Config]: close failed"; errs[1].Error() != want {
+			t.Errorf("got %q, want %q", errs[1], want)
+		}
+	})
+
+	t.Run("StopOnError", func(t *testing.T) {
+		var log []string
+		_, shutdown := newContainer(&log)
+		err := shutdown.IterateErr(context.Background(), false, StopOnError())
+		if got := strings.Join(log, ","); got != "godi.Config" {
+			t.Errorf("expected iteration to stop at the first failure, got %s", got)
+		}
+		var errs Errors
+		if !errors.As(err, &errs) || len(errs) != 1 {
+			t.Errorf("expected 1 error, got %v", err)
+		}
+	})
+
+	t.Run("IterateIgnoresErrors", func(t *testing.T) {
+		var log []string
+		_, shutdown := newContainer(&log)
+		shutdown.Iterate(context.Background(), false)
+		if len(log) != 3 {
+			t.Errorf("expected 3 callbacks, got %v", log)
+		}
+	})
+
+	t.Run("PlainHooksNeverFail", func(t *testing.T) {
+		c := (&Container{}).MustAdd(Provide(Config{}))
+		var hookErrs int
+		hook := c.HookErr("each", func(v any, provided int) func(context.Context) error {
+			return func(context.Context) error { hookErrs++; return nil }
+		})
+		plain := c.Hook("plain", func(v any, provided int) func(context.Context) {
+			return func(context.Context) {}
+		})
+		_, _ = Inject[Config](c)
+		_, _ = Inject[Config](c)
+		if err := hook.IterateErr(context.Background(), false); err != nil || hookErrs != 2 {
+			t.Errorf("unexpected result %v after %d calls", err, hookErrs)
+		}
+		if err := plain.IterateErr(context.Background(), false); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// Callback Iteration Tests
+// =============================================================================
+
+func TestHooks_Run(t *testing.T) {
+	newHooked := func(build func(v any) func(context.Context) error) Callbacks {
+		c := (&Container{}).MustAdd(Provide(Config{}), Provide(&Database{}), Provide(42))
+		hook := c.HookOnceErr("shutdown", build)
+		_, _ = Inject[Config](c)
+		_, _ = Inject[*Database](c)
+		_, _ = Inject[int](c)
+		return hook
+	}
+
+	t.Run("Summary", func(t *testing.T) {
+		hook := newHooked(func(v any) func(context.Context) error {
+			return func(context.Context) error {
+				if _, ok := v.(int); ok {
+					return errors.New("boom")
+				}
+				return nil
+			}
+		})
+		s := hook.Run(context.Background(), false)
+		if got := strings.Join(s.Ran, ","); got != "shutdown [godi.Config],shutdown [*godi.Database]" {
+			t.Errorf("unexpected ran %s", got)
+		}
+		if len(s.Failed) != 1 || s.Failed[0].Type != "int" || s.Skipped != 0 || s.Err != nil {
+			t.Errorf("unexpected summary %+v", s)
+		}
+	})
+
+	t.Run("CancellationBetweenCallbacks", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var ran []any
+		hook := newHooked(func(v any) func(context.Context) error {
+			return func(context.Context) error {
+				ran = append(ran, v)
+				cancel()
+				return nil
+			}
+		})
+		s := hook.Run(ctx, false)
+		if len(ran) != 1 || len(s.Ran) != 1 || s.Skipped != 2 || !errors.Is(s.Err, context.Canceled) {
+			t.Errorf("unexpected summary %+v after %v", s, ran)
+		}
+		if err := s.Errors(); !errors.Is(err, context.Canceled) || err.Error() != "2 callbacks skipped: context canceled" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("TimeoutNamesStuckComponent", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		hook := newHooked(func(v any) func(context.Context) error {
+			return func(context.Context) error {
+				if _, ok := v.(*Database); ok {
+					<-release // ignores its context
+				}
+				return nil
+			}
+		})
+		start := time.Now()
+		err := hook.IterateErr(context.Background(), false, CallbackTimeout(20*time.Millisecond))
+		if time.Since(start) > time.Second {
+			t.Fatal("iteration waited for the stuck callback")
+		}
+		var herr *HookError
+		if !errors.As(err, &herr) || herr.Type != "*godi.Database" || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected the stuck component to be named, got %v", err)
+		}
+		if want := "hook shutdown [*godi.Database]: callback did not return: context deadline exceeded"; err.Error() != want {
+			t.Errorf("got %q, want %q", err, want)
+		}
+	})
+
+	t.Run("BoundedParallel", func(t *testing.T) {
+		var running, peak int32
+		var fns []func(context.Context)
+		for i := 0; i < 6; i++ {
+			fns = append(fns, func(context.Context) {
+				n := atomic.AddInt32(&running, 1)
+				for {
+					p := atomic.LoadInt32(&peak)
+					if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			})
+		}
+		cbs := Callbacks(func(f func([]func(context.Context))) { f(fns) })
+		s := cbs.Run(context.Background(), true, Parallel(2))
+		if peak != 2 || len(s.Ran) != 6 || s.Ran[0][:10] != "callback #" {
+			t.Errorf("expected at most 2 concurrent callbacks, got peak %d and %v", peak, s.Ran)
+		}
+	})
+
+	t.Run("StopOnErrorSkipsTheRest", func(t *testing.T) {
+		var calls int32
+		var fns []func(context.Context)
+		c := (&Container{}).MustAdd(Provide(Config{}))
+		hook := c.HookErr("each", func(v any, provided int) func(context.Context) error {
+			return func(context.Context) error { atomic.AddInt32(&calls, 1); return errors.New("boom") }
+		})
+		for i := 0; i < 5; i++ {
+			_, _ = Inject[Config](c)
+		}
+		hook(func(f []func(context.Context)) { fns = f })
+		s := hook.Run(context.Background(), false, Parallel(1), StopOnError())
+		if calls != 1 || len(s.Failed) != 1 || s.Skipped != len(fns)-1 || s.Err != nil {
+			t.Errorf("unexpected summary %+v after %d calls", s, calls)
+		}
+	})
+}
+
+// =============================================================================
+// Dependency Ordered Hook Tests
+// =============================================================================
+
+func TestHooks_DependencyOrder(t *testing.T) {
+	names := func(cbs Callbacks, reverse bool) string {
+		s := cbs.Run(context.Background(), reverse)
+		return strings.Join(s.Ran, ",")
+	}
+
+	t.Run("IndependentOfInjectionOrder", func(t *testing.T) {
+		infra := (&Container{}).MustAdd(Provide(&Database{DSN: "db"}))
+		c := (&Container{}).MustAdd(
+			Build(func(c *Container) (*Service, error) {
+				return &Service{Name: "svc"}, nil
+			}, Requires[*Database]()),
+			Build(func(svc *Service) (Config, error) { return Config{AppName: svc.Name}, nil }),
+			infra,
+		)
+		shutdown := c.HookOnce("shutdown", func(v any) func(context.Context) {
+			return func(context.Context) {}
+		})
+		// Record order: Service, Config, Database
+		_, _ = Inject[Config](c)
+		_, _ = Inject[*Database](c)
+
+		if got, want := names(shutdown, true), "shutdown [godi.Config],shutdown [*godi.Service],shutdown [*godi.Database]"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+		if got, want := names(shutdown, false), "shutdown [*godi.Database],shutdown [*godi.Service],shutdown [godi.Config]"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("ConcurrentInjection", func(t *testing.T) {
+		c := (&Container{}).MustAdd(
+			Provide(&Database{}),
+			Build(func(db *Database) (Config, error) { return Config{}, nil }),
+			Build(func(cfg Config) (*Service, error) { return &Service{}, nil }),
+		)
+		shutdown := c.HookOnce("shutdown", func(v any) func(context.Context) {
+			return func(context.Context) {}
+		})
+		var wg sync.WaitGroup
+		for _, inject := range []func(){
+			func() { _, _ = Inject[*Service](c) },
+			func() { _, _ = Inject[*Database](c) },
+			func() { _, _ = Inject[Config](c) },
+		} {
+			wg.Add(1)
+			go func(inject func()) { defer wg.Done(); inject() }(inject)
+		}
+		wg.Wait()
+		if got, want := names(shutdown, true), "shutdown [*godi.Service],shutdown [godi.Config],shutdown [*godi.Database]"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+}
+
+// =============================================================================
+// Typed Hook Tests
+// =============================================================================
+
+func TestHooks_HookFor(t *testing.T) {
+	t.Run("ConcreteAndInterface", func(t *testing.T) {
+		c := (&Container{}).MustAdd(
+			Provide(&Database{DSN: "db"}),
+			Provide(&mysqlRepo{}),
+			ProvideNamed("replica", Database{DSN: "replica"}),
+			Provide(Config{}),
+		)
+		var log []string
+		dbs := HookFor(c, "shutdown", func(db *Database, info HookInfo) func(context.Context) {
+			return func(context.Context) { log = append(log, fmt.Sprintf("db %s %s %d", db.DSN, info.Type, info.Provided)) }
+		})
+		pingers := HookFor(c, "shutdown", func(p Pinger, info HookInfo) func(context.Context) {
+			return func(context.Context) { log = append(log, "pinger "+info.Type) }
+		})
+		replicas := HookFor(c, "shutdown", func(db Database, info HookInfo) func(context.Context) {
+			if info.Provided > 0 {
+				return nil
+			}
+			return func(context.Context) { log = append(log, "replica "+info.Type) }
+		})
+		untyped := c.HookOnce("shutdown", func(v any) func(context.Context) {
+			return func(context.Context) { log = append(log, fmt.Sprintf("any %T", v)) }
+		})
+
+		_, _ = Inject[*Database](c)
+		_, _ = Inject[*mysqlRepo](c)
+		_, _ = InjectNamed[Database](c, "replica")
+		_, _ = InjectNamed[Database](c, "replica")
+		_, _ = Inject[Config](c)
+
+		JoinCallbacks(dbs, pingers, replicas, untyped).Iterate(context.Background(), false)
+		// Merged by type in registration (dependency) order; the hooks of one type
+		// fire in no particular order
+		want := [][]string{
+			{"db db *godi.Database 0", "any *godi.Database"},
+			{"pinger *godi.mysqlRepo", "any *godi.mysqlRepo"},
+			{`replica godi.Database "replica"`, "any godi.Database"},
+			{"any godi.Config"},
+		}
+		var got [][]string
+		for i := 0; i < len(log); {
+			n := len(want[len(got)])
+			group := append([]string(nil), log[i:i+n]...)
+			sort.Strings(group)
+			sort.Strings(want[len(got)])
+			got, i = append(got, group), i+n
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("got %q, want %q", log, want)
+		}
+	})
+
+	t.Run("JoinedInDependencyOrder", func(t *testing.T) {
+		var log []string
+		c := (&Container{}).MustAdd(
+			Build(func(cfg Config) (*Database, error) { return &Database{}, nil }),
+			Provide(Config{}),
+		)
+		typed := HookFor(c, "shutdown", func(db *Database, info HookInfo) func(context.Context) {
+			return func(context.Context) { log = append(log, "stop "+info.Type) }
+		})
+		plain := c.HookOnce("shutdown", func(v any) func(context.Context) {
+			if _, ok := v.(Config); !ok {
+				return nil
+			}
+			return func(context.Context) { log = append(log, "stop godi.Config") }
+		})
+		_, _ = Inject[*Database](c)
+
+		JoinCallbacks(typed, plain).Iterate(context.Background(), true)
+		if got := strings.Join(log, ","); got != "stop *godi.Database,stop godi.Config" {
+			t.Errorf("expected the dependent stopped first, got %s", got)
+		}
+		log = nil
+		JoinCallbacks(plain, JoinCallbacks(typed)).Iterate(context.Background(), false)
+		if got := strings.Join(log, ","); got != "stop godi.Config,stop *godi.Database" {
+			t.Errorf("expected dependencies first in nested joins, got %s", got)
+		}
+	})
+
+	t.Run("OtherCallbacksLast", func(t *testing.T) {
+		var log []string
+		c := (&Container{}).MustAdd(Provide(Config{}))
+		typed := HookFor(c, "shutdown", func(cfg Config, info HookInfo) func(context.Context) {
+			return func(context.Context) { log = append(log, "hook") }
+		})
+		_, _ = Inject[Config](c)
+		other := Callbacks(func(f func([]func(context.Context))) {
+			f([]func(context.Context){func(context.Context) { log = append(log, "other") }})
+		})
+		joined := JoinCallbacks(other, typed)
+		if len(log) != 0 {
+			t.Fatalf("expected nothing run while joining, got %v", log)
+		}
+		joined.Iterate(context.Background(), false)
+		if got := strings.Join(log, ","); got != "hook,other" {
+			t.Errorf("expected other callbacks last, got %s", got)
+		}
+	})
+
+	t.Run("ErrorsAndTimeoutsAcrossJoined", func(t *testing.T) {
+		errClose := errors.New("close failed")
+		c := (&Container{}).MustAdd(Provide(&Database{}), Provide(Config{}))
+		typed := HookFor(c, "init", func(cfg Config, info HookInfo) func(context.Context) {
+			return func(context.Context) {}
+		})
+		failing := c.HookOnceErr("close", func(v any) func(context.Context) error {
+			return func(context.Context) error { return errClose }
+		})
+		_, _ = Inject[Config](c)
+		_, _ = Inject[*Database](c)
+
+		s := JoinCallbacks(typed, failing).Run(context.Background(), false)
+		if len(s.Ran) != 1 || s.Ran[0] != "init [godi.Config]" || len(s.Failed) != 2 || !errors.Is(s.Failed[1], errClose) {
+			t.Errorf("unexpected summary %+v", s)
+		}
+	})
+}
+
 // =============================================================================
 // Benchmark Tests
 // =============================================================================
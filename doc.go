@@ -49,7 +49,21 @@
 //	    return NewDatabase(cfg.DSN), nil
 //	}))
 //
-// Build supports three dependency patterns:
+// Factory registers a transient factory function (new value on every injection):
+//
+//	c.Add(godi.Factory(func(_ struct{}) (*bytes.Buffer, error) {
+//	    return new(bytes.Buffer), nil
+//	}))
+//
+// Build caches constructor errors too; the Retry option retries failed constructions
+// on the next injection instead, with an optional attempt limit and backoff. Build options
+// are accepted by BuildNamed, BuildGroup and BuildMap too:
+//
+//	c.Add(godi.Build(dialDatabase, godi.Retry(5), godi.Backoff(time.Second)))
+//
+// Concurrent injections still share a single construction in flight.
+//
+// Build and Factory support four dependency patterns:
 //
 // Pattern 1: Single dependency (auto-injected)
 //
@@ -71,6 +85,17 @@
 //	    return NewLogger(), nil
 //	})
 //
+// Pattern 4: Multiple dependencies (auto-injected, up to six)
+//
+//	godi.Build2(func(db *Database, cache *Cache) (*Service, error) {
+//	    return NewService(db, cache), nil
+//	})
+//
+//	// Same constructor using a dependency tuple (Deps2..Deps6)
+//	godi.Build(func(d godi.Deps2[*Database, *Cache]) (*Service, error) {
+//	    return NewService(d.A, d.B), nil
+//	})
+//
 // # Injection Methods
 //
 // Generic injection (returns value + error):
@@ -90,6 +115,105 @@
 //
 //	err := c.Inject(&db, &cfg, &cache)
 //
+// Optional injection (tells "not registered" from "failed"):
+//
+//	cache, ok, err := godi.InjectOptional[*Cache](c)  // ok=false, err=nil if not registered
+//
+//	godi.Build(func(cache godi.Optional[*Cache]) (*Service, error) {
+//	    return NewService(cache.Value, cache.Ok), nil
+//	})
+//
+// Lazy injection (defers construction until Get, breaks mutual references):
+//
+//	godi.Build(func(repo godi.Lazy[*Repo]) (*Service, error) {
+//	    return &Service{repo: repo}, nil  // repo.Get() builds *Repo on first use
+//	})
+//
+// Context-aware injection (deadlines and cancellation through the whole chain):
+//
+//	godi.BuildCtx(func(ctx context.Context, cfg Config) (*sql.DB, error) {
+//	    return dial(ctx, cfg.DSN)
+//	})
+//
+//	db, err := godi.InjectCtx[*sql.DB](ctx, c)
+//	var canceled *godi.CanceledError
+//	if errors.As(err, &canceled) { ... }  // also errors.Is(err, context.DeadlineExceeded)
+//
+// Builders using the func(*Container) pattern read the context with c.Context().
+// Callers waiting for a singleton built by another goroutine give up when their own
+// context is done, and constructions failing because their context is done are not cached.
+//
+// Missing providers return an error wrapping ErrNotFound:
+//
+//	if errors.Is(err, godi.ErrNotFound) { ... }
+//
+// # Named Providers
+//
+// Several providers of the same type can be registered under different names.
+// Duplicates are detected per (type, name) pair:
+//
+//	c.MustAdd(
+//	    godi.ProvideNamed("primary", primaryDB),
+//	    godi.BuildNamed("replica", func(cfg Config) (*sql.DB, error) {
+//	        return sql.Open("mysql", cfg.ReplicaDSN)
+//	    }),
+//	)
+//	replica, err := godi.InjectNamed[*sql.DB](c, "replica")
+//
+// Build arguments select a name with a Qualifier marker type:
+//
+//	type Replica struct{}
+//	func (Replica) Name() string { return "replica" }
+//
+//	godi.Build(func(db godi.Named[*sql.DB, Replica]) (*Reports, error) {
+//	    return NewReports(db.Value), nil
+//	})
+//
+// # Value Groups
+//
+// Groups collect many contributions of one type; the consumer injects []T.
+// Contributions from nested containers are included, in registration order:
+//
+//	c.MustAdd(
+//	    godi.ProvideGroup[HealthCheck](cacheCheck),
+//	    godi.BuildGroup(func(db *Database) (HealthCheck, error) { return db.Ping, nil }),
+//	)
+//	checks, err := godi.Inject[[]HealthCheck](c)
+//
+// # Map Bindings
+//
+// Map entries contribute values under string keys; the consumer injects map[string]T.
+// Registering a key twice fails at Add time, naming both registrations:
+//
+//	c.MustAdd(
+//	    godi.ProvideMap[Storage]("local", disk.New()),
+//	    godi.BuildMap("s3", func(cfg Config) (Storage, error) { return s3.New(cfg.Bucket) }),
+//	)
+//	drivers, err := godi.Inject[map[string]Storage](c)
+//
+// # Interface Binding
+//
+// Bind exposes a provider of a concrete type under an interface as well.
+// Both types share the same singleton, hooks fire once for the concrete type,
+// and Add reports conflicts with other providers of the interface:
+//
+//	c.MustAdd(godi.Bind[UserRepository, *MySQLRepo](
+//	    godi.Build(func(db *sql.DB) (*MySQLRepo, error) { return NewMySQLRepo(db), nil }),
+//	))
+//	repo, err := godi.Inject[UserRepository](c)
+//
+// # Decorators
+//
+// Decorate wraps values of a type registered elsewhere when they are constructed.
+// Decorators stack in registration order and apply across nested containers;
+// a failing decorator is named (with its registration site) in the error.
+// Interfaces exposed with Bind are not decorated (decorate the implementation type);
+// Validate reports decorators of a type no provider constructs:
+//
+//	c.MustAdd(godi.Decorate(func(db *Database) (*Database, error) {
+//	    return db.WithMetrics(), nil
+//	}))
+//
 // # Container Nesting
 //
 // Containers can be nested to create modular, tree-structured applications.
@@ -137,6 +261,98 @@
 //	    return godi.Inject[T](c)
 //	}))
 //
+// # Scopes
+//
+// NewScope creates a scope container for request, session or job lifetimes.
+// Scoped providers are built once per scope, Build singletons stay shared with the root,
+// and Dispose closes only the instances owned by the scope. The root is not frozen.
+//
+//	root.MustAdd(godi.Scoped(func(db *Database) (*Tx, error) {
+//	    return db.Begin()
+//	}))
+//
+//	req := root.NewScope()
+//	defer req.Dispose()  // closes the scope's *Tx
+//	tx := godi.MustInject[*Tx](req)
+//
+// # Overrides and Overlays
+//
+// Override replaces providers instead of rejecting duplicates. Overlay creates a
+// container shadowing a base container: overrides on the overlay replace types anywhere
+// in the base tree (even inside frozen children), while the base container, its
+// singletons and its hooks stay untouched.
+//
+//	test := prod.Overlay()
+//	test.MustOverride(godi.Provide[Database](&MockDatabase{}))
+//	svc := godi.MustInject[*UserService](test)  // built with the mock
+//
+// # Warmup
+//
+// Everything is lazy by default. Warmup builds every lazy singleton of the container tree
+// at boot, running independent constructors in parallel, and returns all failures at once:
+//
+//	if err := c.Warmup(ctx, 4); err != nil {  // at most 4 constructors at a time
+//	    var errs godi.Errors  // one error per failing provider
+//	    errors.As(err, &errs)
+//	}
+//
+// # Validation
+//
+// Validate checks the declared dependency graph without constructing anything,
+// reporting every missing dependency and every cycle (handy in a CI test):
+//
+//	func TestWiring(t *testing.T) {
+//	    if err := wire.NewContainer().Validate(); err != nil {
+//	        t.Fatal(err)
+//	    }
+//	}
+//
+// Dependencies are declared by the Build argument type; builders using the
+// func(*Container) pattern declare theirs with Requires, accepted by every builder
+// (Build, Factory, Scoped, BuildNamed, BuildGroup, BuildMap...):
+//
+//	godi.Build(func(c *godi.Container) (*Service, error) {
+//	    return NewService(godi.MustInject[*Database](c)), nil
+//	}, godi.Requires[*Database]())
+//
+// # Introspection
+//
+// Providers lists what a container tree holds, in registration order: the provided
+// type, the provider kind, whether its singleton is built (and the construction error),
+// which nested container owns it and the interfaces it is bound to. Decorators are not
+// listed. Walk visits the same entries with early exit:
+//
+//	for _, p := range c.Providers() {
+//	    fmt.Println(p.Owner, p.Type, p.Kind, p.Built, p.Err)
+//	}
+//
+// # Dependency Graph
+//
+// Graph returns the providers of the container tree (type, kind, owning container)
+// and their dependencies, both declared and observed during real injections,
+// with encoders for Graphviz DOT, Mermaid and JSON. Interfaces exposed with Bind
+// are aliases of the bound type's node rather than nodes of their own:
+//
+//	_ = c.Warmup(ctx, 0)  // observe the injections of func(*Container) builders
+//	g := c.Graph()
+//	os.WriteFile("deps.dot", []byte(g.DOT()), 0o644)
+//	fmt.Println(g.Mermaid())
+//
+// # Start and Stop
+//
+// Start builds every singleton (see Warmup) and calls Start(ctx) on the built components
+// implementing Starter, each after the components it depends on; if one fails, those
+// already started are stopped again. Components are started once, even if Start is
+// called again. Stop runs in reverse dependency order: every built Stopper gets Stop(ctx)
+// and every built io.Closer is closed, once, even if Stop is called again. Both return all
+// failures as Errors. Only values constructed by Build providers are managed; an Overlay
+// manages its own copies, while a scope from NewScope leaves the shared singletons to its parent:
+//
+//	if err := c.Start(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer c.Stop(shutdownCtx)
+//
 // # Hook Lifecycle
 //
 // Hooks allow registering callbacks that execute when dependencies are injected.
@@ -160,11 +376,76 @@
 //
 //	shutdown.Iterate(ctx, false)  // false = forward order
 //
+// Callbacks are ordered by the dependency graph (see Graph), not by injection order:
+// forward runs dependencies first and reverse (shutdown) runs each component before
+// the things it depends on, even when injections happen concurrently.
+//
+// HookFor registers a typed hook, firing only for values of a type or implementing an
+// interface, with a HookInfo (hook name, provided type, injection count). JoinCallbacks
+// iterates typed and untyped hooks together, merged in dependency order:
+//
+//	closers := godi.HookFor(c, "shutdown", func(cl io.Closer, info godi.HookInfo) func(context.Context) {
+//	    return func(context.Context) { cl.Close() }
+//	})
+//	godi.JoinCallbacks(closers, shutdown).Iterate(ctx, true)
+//
+// HookErr and HookOnceErr build callbacks returning an error. IterateErr runs every
+// callback and returns the failures as Errors of *HookError, each naming the hook and
+// the instance it was built for; StopOnError skips the rest after the first failure:
+//
+//	shutdown := c.HookOnceErr("shutdown", func(v any) func(context.Context) error {
+//	    if closer, ok := v.(io.Closer); ok {
+//	        return func(context.Context) error { return closer.Close() }
+//	    }
+//	    return nil
+//	})
+//	if err := shutdown.IterateErr(ctx, true); err != nil { ... }
+//
+// Run does the same and returns a Summary of what ran, failed or was skipped.
+// Both stop once ctx is done (checked between callbacks) and accept options:
+// CallbackTimeout abandons a stuck callback and reports it by instance, and
+// Parallel runs independent callbacks concurrently:
+//
+//	s := shutdown.Run(ctx, true, godi.CallbackTimeout(5*time.Second), godi.Parallel(4))
+//	fmt.Println(s.Ran, s.Failed, s.Skipped)
+//
 // Hook Behavior in Nested Containers:
 // - Hooks trigger on each container in the injection path
 // - Each container maintains independent `provided` counters per type
 // - Execute hooks for each container separately
 //
+// # Errors
+//
+// Failures about a type are *ContainerError values wrapping a sentinel, and failing
+// constructors are reported as *BuildError wrapping their cause:
+//
+//	errors.Is(err, godi.ErrNotFound)   // no provider for the type
+//	errors.Is(err, godi.ErrCircular)   // the type depends on itself
+//	errors.Is(err, godi.ErrDuplicate)  // Add: the type is already provided
+//	errors.Is(err, godi.ErrFrozen)     // Add: the container is a child of another one
+//
+//	var cerr *godi.ContainerError
+//	if errors.As(err, &cerr) {
+//	    fmt.Println(cerr.Type)  // e.g. *main.Database
+//	}
+//
+//	var berr *godi.BuildError
+//	if errors.As(err, &berr) {
+//	    fmt.Println(berr.Type, berr.Err)  // type being built and the cause
+//	}
+//
+// Errors carry the resolution path leading to the failure, and circular dependency
+// errors print the whole cycle:
+//
+//	godi.ResolutionPath(err)  // [*main.Service main.Repo main.Config]
+//	// provider [main.Config] not found (resolving *main.Service -> main.Repo -> main.Config)
+//	// circular dependency for [main.A]: main.A -> main.B -> main.A
+//
+// Not-found errors suggest registered types close to the missing one: pointer/value
+// mismatches, the same type name in another package, and interface implementations:
+//
+//	// provider [main.Database] not found; did you mean *main.Database (pointer/value mismatch)?
+//
 // # Circular Dependency Detection
 //
 // Godi automatically detects circular dependencies at runtime.
@@ -174,9 +455,12 @@
 //
 // Detection mechanism:
 //  1. Create temporary container context during injection
-//  2. Mark types being injected with depth tracking
-//  3. If marked type encountered, return circular dependency error
-//  4. Clean up markers after injection completes
+//  2. Record the types being injected in a chain carried by the context
+//  3. If a type still in the chain is encountered, return circular dependency error
+//  4. Mark the link as completed after injection returns
+//
+// Lazy handles keep the context they were injected from; calling Get once the
+// constructors that captured them returned is therefore not a cycle.
 //
 // # Concurrency Safety
 //
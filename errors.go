@@ -0,0 +1,120 @@
+package godi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors matched with errors.Is.
+var (
+	// ErrNotFound is wrapped by the error returned when no provider matches the requested type.
+	// Use InjectOptional to tell a type that is not registered from a failing provider.
+	ErrNotFound = errors.New("not found")
+	// ErrCircular is wrapped by the error returned when a type depends on itself.
+	ErrCircular = errors.New("circular dependency")
+	// ErrDuplicate is wrapped by the error returned by Add when a type is already provided.
+	ErrDuplicate = errors.New("already exists")
+	// ErrFrozen is wrapped by the error returned when adding to a container that was
+	// added as a child of another container.
+	ErrFrozen = errors.New("container frozen")
+)
+
+// ContainerError describes a failure about a type in a container: not found (ErrNotFound),
+// circular (ErrCircular), already registered (ErrDuplicate) or registration in a frozen
+// container (ErrFrozen). errors.Is matches the sentinel and errors.As extracts the details.
+type ContainerError struct {
+	Err       error      // ErrNotFound, ErrCircular, ErrDuplicate or ErrFrozen
+	Type      string     // Offending type, e.g. *main.Database or main.Database "replica"
+	Path      []string   // ErrNotFound and ErrCircular: types being resolved, outermost first, ending with Type
+	Conflict  string     // ErrDuplicate only: the new registration, when described differently
+	Container *Container // Container searched or modified
+
+	// Suggestions lists, for ErrNotFound, registered types close to Type with the reason,
+	// e.g. "*main.Database (pointer/value mismatch)".
+	Suggestions []string
+}
+
+func (e *ContainerError) Error() string {
+	switch e.Err {
+	case ErrNotFound:
+		msg := fmt.Sprintf("provider [%s] %v", e.Type, e.Err)
+		if len(e.Path) > 1 {
+			msg += fmt.Sprintf(" (resolving %s)", strings.Join(e.Path, " -> "))
+		}
+		if len(e.Suggestions) > 0 {
+			msg += fmt.Sprintf("; did you mean %s?", strings.Join(e.Suggestions, ", "))
+		}
+		return msg
+	case ErrCircular:
+		return fmt.Sprintf("%v for [%s]: %s", e.Err, e.Type, strings.Join(e.Cycle(), " -> "))
+	case ErrFrozen:
+		return fmt.Sprintf("%v: already provided as child", e.Err)
+	case ErrDuplicate:
+		if e.Conflict != "" {
+			return fmt.Sprintf("provider [%s] %v: conflicts with [%s]", e.Type, e.Err, e.Conflict)
+		}
+	}
+	return fmt.Sprintf("provider [%s] %v", e.Type, e.Err)
+}
+
+func (e *ContainerError) Unwrap() error { return e.Err }
+
+// Cycle returns the part of Path forming the cycle of an ErrCircular error,
+// from the previous occurrence of Type to its repetition, e.g. [A B C A].
+func (e *ContainerError) Cycle() []string {
+	for i := len(e.Path) - 2; i >= 0; i-- {
+		if e.Path[i] == e.Type {
+			return e.Path[i:]
+		}
+	}
+	return e.Path
+}
+
+// BuildError is returned when the constructor of a type (or one of its decorators) fails.
+// It wraps the cause, which may itself be the error of a dependency.
+type BuildError struct {
+	Type      string     // Type being built, e.g. *main.Database
+	Path      []string   // Types being resolved, outermost first, ending with Type
+	Container *Container // Container owning the provider
+	Err       error      // Cause of the failure
+}
+
+func (e *BuildError) Error() string { return fmt.Sprintf("build [%s] error: %v", e.Type, e.Err) }
+
+func (e *BuildError) Unwrap() error { return e.Err }
+
+// typeOf returns the type name of a provider id or injection target without brackets,
+// e.g. *godi.Database.
+func typeOf(ptr any) string {
+	return strings.TrimSuffix(strings.TrimPrefix(typName(ptr), "["), "]")
+}
+
+// buildError wraps the failure to build ptr in the context c.
+// The top of the resolution chain of c is the provider being built.
+func buildError(c *Container, ptr any, err error) error {
+	path := []string{typeOf(ptr)}
+	if c.resolving != nil {
+		path = c.resolving.parent.path(c.resolving.id)
+	}
+	return &BuildError{Type: typeOf(ptr), Path: path, Container: c.real(), Err: err}
+}
+
+// ResolutionPath returns the most complete resolution path carried by err or the
+// errors it wraps (see ContainerError.Path and BuildError.Path), outermost type first.
+// Example: [*main.Service main.Repo main.Config]
+func ResolutionPath(err error) (path []string) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var p []string
+		switch e := err.(type) {
+		case *ContainerError:
+			p = e.Path
+		case *BuildError:
+			p = e.Path
+		}
+		if len(p) >= len(path) {
+			path = p
+		}
+	}
+	return
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/Just-maple/godi"
@@ -52,7 +53,7 @@ func gracefulErrorHandling() {
 
 	// Duplicate registration returns error
 	err = c.Add(godi.Provide(Database{DSN: "mysql://remote"}))
-	if err != nil {
+	if errors.Is(err, godi.ErrDuplicate) {
 		fmt.Printf("✓ Expected duplicate error: %v\n", err)
 	}
 
@@ -66,8 +67,9 @@ func gracefulErrorHandling() {
 
 	// Injecting non-existent dependency returns error
 	_, err = godi.Inject[CriticalConfig](c)
-	if err != nil {
-		fmt.Printf("✓ Expected not-found error: %v\n", err)
+	var cerr *godi.ContainerError
+	if errors.As(err, &cerr) && errors.Is(err, godi.ErrNotFound) {
+		fmt.Printf("✓ Expected not-found error for %s: %v\n", cerr.Type, err)
 	}
 }
 
@@ -60,18 +60,14 @@ func main() {
 	)
 
 	// Test configuration (using Mock)
-	testContainer := &godi.Container{}
+	// The overlay shadows the production container without mutating it
 	mockDB := &MockDatabase{
 		Data: []map[string]interface{}{
 			{"id": 1, "name": "Test User", "email": "test@example.com"},
 		},
 	}
-	testContainer.MustAdd(
-		godi.Provide[Database](mockDB),
-		godi.Build(func(db Database) (*UserService, error) {
-			return NewUserService(db), nil
-		}),
-	)
+	testContainer := prodContainer.Overlay()
+	testContainer.MustOverride(godi.Provide[Database](mockDB))
 
 	// Use test container
 	testUserSvc, _ := godi.Inject[*UserService](testContainer)
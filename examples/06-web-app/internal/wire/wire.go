@@ -23,20 +23,8 @@ import (
 func NewAppContainer() *godi.Container {
 	c := &godi.Container{}
 
-	// Register shutdown hook using HookOnce for automatic single execution
-	shutdown := c.HookOnce("shutdown", func(v any) func(context.Context) {
-		return func(ctx context.Context) {
-			// Execute cleanup for closable resources using interface assertion
-			if closer, ok := v.(interface{ Close() error }); ok {
-				if err := closer.Close(); err != nil {
-					fmt.Printf("[Cleanup] Error closing %T: %v\n", v, err)
-				}
-			}
-		}
-	})
-
 	// Register Config (concrete type - no interface needed for config)
-	// Register Infrastructure (Build with cleanup hooks)
+	// Register Infrastructure (built instances implementing io.Closer are closed by Stop)
 	// Note: We register concrete types but depend on interfaces in upper layers
 	c.MustAdd(
 		godi.Provide(config.NewConfig()),
@@ -49,41 +37,21 @@ func NewAppContainer() *godi.Container {
 		godi.Build(func(db interfaces.Database) (repository.UserRepositoryInterface, error) {
 			return repository.NewUserRepository(db), nil
 		}),
-		godi.Build(func(repo repository.UserRepositoryInterface) (service.UserServiceInterface, error) {
-			cache, err := godi.Inject[interfaces.Cache](c)
-			if err != nil {
-				return nil, err
-			}
+		godi.Build2(func(repo repository.UserRepositoryInterface, cache interfaces.Cache) (service.UserServiceInterface, error) {
 			return service.NewUserService(repo, cache), nil
 		}),
 		godi.Provide(handler.NewRouter()),
-		godi.Build(func(svc service.UserServiceInterface) (interfaces.Handler, error) {
-			router, err := godi.Inject[*handler.Router](c)
-			if err != nil {
-				return nil, err
-			}
+		godi.Build2(func(svc service.UserServiceInterface, router *handler.Router) (interfaces.Handler, error) {
 			return handler.NewUserHandler(svc, router), nil
 		}),
 		godi.Build(func(cfg *config.Config) (interfaces.Middleware, error) {
 			return middleware.NewLoggingMiddleware(cfg.Debug), nil
 		}),
-		godi.Build(func(cfg *config.Config) (*app.App, error) {
-			router := app.NewRouter()
-			h, err := godi.Inject[interfaces.Handler](c)
-			if err != nil {
-				return nil, err
-			}
-			mw, err := godi.Inject[interfaces.Middleware](c)
-			if err != nil {
-				return nil, err
-			}
-			return app.NewApp(cfg, router, h, mw), nil
+		godi.Build3(func(cfg *config.Config, h interfaces.Handler, mw interfaces.Middleware) (*app.App, error) {
+			return app.NewApp(cfg, app.NewRouter(), h, mw), nil
 		}),
 	)
 
-	// Store shutdown executor in container for later retrieval
-	c.MustAdd(godi.Provide(shutdown))
-
 	return c
 }
 
@@ -92,18 +60,13 @@ func Run() error {
 	container := NewAppContainer()
 	fmt.Println("✓ Container created")
 	fmt.Println("✓ Using Dependency Inversion Principle")
-	fmt.Println("✓ Shutdown hooks registered via HookOnce")
+	fmt.Println("✓ Shutdown handled by container.Stop")
 
 	appInstance, err := godi.Inject[*app.App](container)
 	if err != nil {
 		return fmt.Errorf("failed to inject App: %w", err)
 	}
 
-	shutdown, err := godi.Inject[godi.Callbacks](container)
-	if err != nil {
-		return fmt.Errorf("failed to inject shutdown: %w", err)
-	}
-
 	fmt.Println("✓ All dependencies injected")
 	fmt.Println()
 
@@ -112,12 +75,14 @@ func Run() error {
 		return err
 	}
 
-	// Perform graceful shutdown using hooks
+	// Perform graceful shutdown in reverse dependency order
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	fmt.Println("\n=== Starting Graceful Shutdown ===")
-	shutdown.Iterate(shutdownCtx, true) // true = reverse order (LIFO)
+	if err := container.Stop(shutdownCtx); err != nil {
+		fmt.Printf("[Cleanup] %v\n", err)
+	}
 	fmt.Println("=== Shutdown Complete ===")
 
 	return nil
@@ -0,0 +1,319 @@
+package godi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind tells how a provider constructs its values.
+type Kind uint8
+
+const (
+	KindValue     Kind = iota + 1 // Provide, ProvideNamed, ProvideGroup, ProvideMap
+	KindBuild                     // Build, BuildCtx, Build2..Build6 and their named, group and map variants
+	KindFactory                   // Factory
+	KindScoped                    // Scoped
+	KindContainer                 // Child container added with Add (or the base of a scope or overlay)
+)
+
+var kindNames = map[Kind]string{
+	KindValue: "value", KindBuild: "build", KindFactory: "factory", KindScoped: "scoped", KindContainer: "container",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText renders the kind by name in JSON.
+func (k Kind) MarshalText() ([]byte, error) { return []byte(k.String()), nil }
+
+// info describes a provider for Validate, Graph and Providers.
+type info struct {
+	kind Kind
+	deps []requirement // Declared dependencies
+	seen sync.Map      // Dependency ids resolved at runtime
+	l    *instance     // Singleton of Provide and Build, as built by the base container
+}
+
+// infoOf returns the description of p, unwrapping named, group, map, bound
+// and overriding providers. It returns nil for providers without one.
+func infoOf(p Provider) *info {
+	for {
+		switch v := p.(type) {
+		case interface{ describe() *info }:
+			return v.describe()
+		case interface{ unwrap() Provider }:
+			p = v.unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// node is a provider visited by Validate and Graph.
+type node struct {
+	p     Provider
+	name  string
+	owner string // Path of the owning container: root, root/0, root/0/1...
+}
+
+// id returns the type name of the node without brackets, e.g. *main.Database.
+func (n node) id() string { return strings.TrimSuffix(strings.TrimPrefix(n.name, "["), "]") }
+
+type nodes []node
+
+// nodes collects every provider visible from c, walking nested child containers
+// like Container.Provide, ordered by name.
+func (c *Container) nodes() nodes {
+	var all nodes
+	c.walk(func(id any, p Provider, owner string) bool {
+		if _, sub := p.(*Container); !sub {
+			all = append(all, node{p, typName(id), owner})
+		}
+		return true
+	})
+	sort.SliceStable(all, func(i, j int) bool { return all[i].name < all[j].name })
+	return all
+}
+
+// resolve returns the nodes answering id, keeping only the highest lookup rank.
+func (ns nodes) resolve(id any) (found []int) {
+	rank := 0
+	for i, n := range ns {
+		if _, ok := n.p.Provide(id); ok {
+			if r := rankOf(n.p); r > rank {
+				found, rank = found[:0], r
+			}
+			if rankOf(n.p) == rank {
+				found = append(found, i)
+			}
+		}
+	}
+	return
+}
+
+// ordered returns the providers visible from c in registration order (see walk) and
+// the order in which they can be constructed: each provider after the providers it
+// depends on, declared or observed. Independent providers keep their registration
+// order and cycles (through Lazy) are broken at the first provider visited.
+func (c *Container) ordered() (all nodes, order []int) {
+	c.walk(func(id any, p Provider, owner string) bool {
+		if _, sub := p.(*Container); !sub {
+			all = append(all, node{p, typName(id), owner})
+		}
+		return true
+	})
+	visited := make([]bool, len(all))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		if in := infoOf(all[i].p); in != nil {
+			for _, r := range in.deps {
+				for _, j := range all.resolve(r.id) {
+					visit(j)
+				}
+			}
+			in.seen.Range(func(id, _ any) bool {
+				for _, j := range all.resolve(id) {
+					visit(j)
+				}
+				return true
+			})
+		}
+		order = append(order, i)
+	}
+	for i := range all {
+		visit(i)
+	}
+	return
+}
+
+// dependencyRank returns, for a provider id, its position in the construction order of
+// the providers visible from c (see ordered), or the number of providers if none answers.
+func (c *Container) dependencyRank() func(id any) int {
+	all, order := c.ordered()
+	pos := make([]int, len(all))
+	for p, i := range order {
+		pos[i] = p
+	}
+	ranks := make(map[any]int)
+	return func(id any) int {
+		if rank, ok := ranks[id]; ok {
+			return rank
+		}
+		rank := len(all)
+		for _, i := range all.resolve(id) {
+			if pos[i] < rank {
+				rank = pos[i]
+			}
+		}
+		ranks[id] = rank
+		return rank
+	}
+}
+
+// Graph is a snapshot of the dependency graph of a container (see Container.Graph).
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a type provided in the container tree.
+// Group members share the node of their group, e.g. []main.HealthCheck.
+type GraphNode struct {
+	ID      string   `json:"id"`                // Type name, e.g. *main.Database or main.Database "replica"
+	Kind    Kind     `json:"kind"`              // How the provider constructs values
+	Owner   string   `json:"owner"`             // Path of the owning container: root, root/0, root/0/1...
+	Aliases []string `json:"aliases,omitempty"` // Interfaces the type is bound to (see Bind)
+}
+
+// as renders the aliases of the node for labels, after sep, or nothing without aliases.
+func (n GraphNode) as(sep string) string {
+	if len(n.Aliases) == 0 {
+		return ""
+	}
+	return sep + "as " + strings.Join(n.Aliases, ", ")
+}
+
+// GraphEdge is a dependency of From on To.
+type GraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Declared bool   `json:"declared"` // Declared by the Build argument type or Requires
+	Observed bool   `json:"observed"` // Resolved by an actual injection
+}
+
+// Graph returns the dependency graph of the providers visible from c, including
+// nested child containers. Edges are declared dependencies (as used by Validate)
+// and dependencies observed during real injections, so builders using the
+// func(*Container) pattern show up once they have been injected (see Warmup).
+// Interfaces exposed with Bind get no node of their own: they are listed in the
+// Aliases of the bound type, and dependencies on them are edges to its node.
+// Decorators are not part of the graph.
+func (c *Container) Graph() *Graph {
+	all := c.nodes()
+	g := new(Graph)
+	added := make(map[string]bool)
+	for _, n := range all {
+		if !added[n.id()] {
+			added[n.id()] = true
+			node := GraphNode{ID: n.id(), Owner: n.owner, Aliases: aliasesOf(n.p)}
+			if i := infoOf(n.p); i != nil {
+				node.Kind = i.kind
+			}
+			g.Nodes = append(g.Nodes, node)
+		}
+	}
+
+	edges := make(map[[2]string]*GraphEdge)
+	edge := func(from node, to any) []*GraphEdge {
+		var found []*GraphEdge
+		for _, j := range all.resolve(to) {
+			key := [2]string{from.id(), all[j].id()}
+			if edges[key] == nil {
+				edges[key] = &GraphEdge{From: key[0], To: key[1]}
+			}
+			found = append(found, edges[key])
+		}
+		return found
+	}
+	for _, n := range all {
+		i := infoOf(n.p)
+		if i == nil {
+			continue
+		}
+		for _, r := range i.deps {
+			for _, e := range edge(n, r.id) {
+				e.Declared = true
+			}
+		}
+		i.seen.Range(func(dep, _ any) bool {
+			for _, e := range edge(n, dep) {
+				e.Observed = true
+			}
+			return true
+		})
+	}
+	for _, e := range edges {
+		g.Edges = append(g.Edges, *e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// DOT renders the graph in the Graphviz DOT language.
+// Nested containers are drawn as clusters and observed-only edges are dashed.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph godi {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	owners := make(map[string][]GraphNode)
+	var order []string
+	for _, n := range g.Nodes {
+		if _, ok := owners[n.Owner]; !ok {
+			order = append(order, n.Owner)
+		}
+		owners[n.Owner] = append(owners[n.Owner], n)
+	}
+	sort.Strings(order)
+	for _, owner := range order {
+		indent := "\t"
+		if owner != "root" {
+			fmt.Fprintf(&b, "\tsubgraph %s {\n\t\tlabel=%s;\n", strconv.Quote("cluster_"+owner), strconv.Quote(owner))
+			indent = "\t\t"
+		}
+		for _, n := range owners[owner] {
+			fmt.Fprintf(&b, "%s%s [label=%s];\n", indent, strconv.Quote(n.ID), strconv.Quote(n.ID+"\n"+n.Kind.String()+n.as("\n")))
+		}
+		if owner != "root" {
+			b.WriteString("\t}\n")
+		}
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if !e.Declared {
+			style = " [style=dashed]"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart.
+// Observed-only edges are dotted.
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(i)
+		label := strings.ReplaceAll(n.ID+" ("+n.Kind.String()+")"+n.as(" "), `"`, "#quot;")
+		fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[n.ID], label)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if !e.Declared {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "\t%s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+	return b.String()
+}
+
+// JSON renders the graph as indented JSON.
+func (g *Graph) JSON() ([]byte, error) { return json.MarshalIndent(g, "", "  ") }
@@ -0,0 +1,238 @@
+package godi
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync/atomic"
+)
+
+// sequence numbers contributions so groups are collected in registration order.
+var sequence int64
+
+// sequenced is implemented by contributions to groups and maps, and by decorators.
+type sequenced interface {
+	sequence() int64
+}
+
+// contribution is a sequenced provider with the context it is built in.
+type contribution[P sequenced] struct {
+	p   P
+	ctx *Container
+}
+
+// gather collects every contribution of kind P visible from c, in registration order.
+// Contributions of nested containers are built in the context of their own container.
+func gather[P sequenced](c *Container) []contribution[P] {
+	seen := make(map[int64]contribution[P])
+	visited := make(map[*Container]bool)
+	var collect func(src *Container, ctx func() *Container)
+	collect = func(src *Container, ctx func() *Container) {
+		src.providers.Range(func(_, p any) bool {
+			switch p := p.(type) {
+			case P:
+				if _, ok := seen[p.sequence()]; !ok {
+					seen[p.sequence()] = contribution[P]{p, ctx()}
+				}
+			case *Container:
+				if p != locked && !visited[p] {
+					visited[p] = true
+					// The child's context is only created if it holds contributions
+					var sub *Container
+					collect(p, func() *Container {
+						if sub == nil {
+							sub = p.fork(ctx())
+						}
+						return sub
+					})
+				}
+			}
+			return true
+		})
+	}
+	collect(c, func() *Container { return c })
+
+	all := make([]contribution[P], 0, len(seen))
+	for _, cb := range seen {
+		all = append(all, cb)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].p.sequence() < all[j].p.sequence() })
+	return all
+}
+
+// memberKey is the provider id of one group contribution.
+type memberKey[T any] struct{ seq int64 }
+
+func (k memberKey[T]) sequence() int64 { return k.seq }
+
+// member is one contribution of type T to the group injected as []T.
+type member[T any] struct {
+	memberKey[T]
+	p provider[T]
+}
+
+// Provide matches the member's own id and the group injection target *[]T.
+func (m member[T]) Provide(v any) (any, bool) {
+	switch k := v.(type) {
+	case memberKey[T]:
+		return m.memberKey, k == m.memberKey
+	case *[]T:
+		return (*[]T)(nil), true
+	}
+	return m.memberKey, false
+}
+
+// aliases exposes the group injection target, so Add rejects mixing the group
+// with a plain provider of []T.
+func (m member[T]) aliases() []any { return []any{(*[]T)(nil)} }
+
+// group returns the id shared by every member of the group.
+func (m member[T]) group() any { return (*[]T)(nil) }
+
+// grouped is implemented by group members, which share the group id with each other.
+type grouped interface{ group() any }
+
+// joins reports whether the providers of c answering id, in nested containers too,
+// are all members of the group of p: sharing the group id is not a conflict then.
+func (c *Container) joins(p Provider, id any) bool {
+	if g, ok := p.(grouped); !ok || g.group() != id {
+		return false
+	}
+	only := true
+	c.providers.Range(func(_, q any) bool {
+		if sub, ok := q.(*Container); ok {
+			only = sub.joins(p, id)
+		} else if _, ok := q.(Provider).Provide(id); ok {
+			_, only = q.(grouped)
+		}
+		return only
+	})
+	return only
+}
+
+// inject collects every member of the group visible from c, in registration order.
+func (m member[T]) inject(c *Container, ptr any) (any, error) {
+	members := gather[member[T]](c)
+	values := make([]T, len(members))
+	for i, cb := range members {
+		if _, err := cb.p.p.f(cb.ctx, &values[i]); err != nil {
+			return nil, err
+		}
+	}
+	*ptr.(*[]T) = values
+	return values, nil
+}
+
+// qualifiedName renders members as the group they contribute to, e.g. [[]T].
+func (k memberKey[T]) qualifiedName() string { return typName((*[]T)(nil)) }
+
+// unwrap returns the provider of the member's value.
+func (m member[T]) unwrap() Provider { return m.p }
+
+// target returns the group injection target when the member is a lazy singleton.
+func (m member[T]) target() any {
+	if m.p.target() != nil {
+		return new([]T)
+	}
+	return nil
+}
+
+// ProvideGroup creates a Provider contributing a value to the group of type T.
+// Any number of contributions can be registered, across nested containers;
+// injecting []T collects all of them in registration order (the order the
+// contributions were created with ProvideGroup/BuildGroup).
+// Example: ProvideGroup[HealthCheck](dbCheck)
+func ProvideGroup[T any](v T) Provider {
+	return member[T]{memberKey[T]{atomic.AddInt64(&sequence, 1)}, Provide(v).(provider[T])}
+}
+
+// BuildGroup is like ProvideGroup but contributes a lazy singleton built by f.
+// Example: BuildGroup(func(db *Database) (HealthCheck, error) { return db.Ping, nil })
+func BuildGroup[R, T any](f func(R) (T, error), opts ...BuildOption) Provider {
+	return member[T]{memberKey[T]{atomic.AddInt64(&sequence, 1)}, Build(f, opts...).(provider[T])}
+}
+
+// entryKey is the provider id of one map entry: the map key and where it was registered.
+type entryKey[T any] struct {
+	key string
+	at  string
+}
+
+// qualifiedName renders the id as [map[string]T "key" (file:line)] for error messages.
+func (k entryKey[T]) qualifiedName() string {
+	return "[" + fmt.Sprintf("%T", (*map[string]T)(nil))[1:] + fmt.Sprintf(" %q (%s)]", k.key, k.at)
+}
+
+// entry is one contribution of type T to the map injected as map[string]T.
+type entry[T any] struct {
+	entryKey[T]
+	seq int64
+	p   provider[T]
+}
+
+func (e entry[T]) sequence() int64 { return e.seq }
+
+// Provide matches entries with the same map key and the map injection target *map[string]T.
+func (e entry[T]) Provide(v any) (any, bool) {
+	switch k := v.(type) {
+	case entryKey[T]:
+		return e.entryKey, k.key == e.key
+	case *map[string]T:
+		return (*map[string]T)(nil), true
+	}
+	return e.entryKey, false
+}
+
+// inject collects every entry of the map visible from c.
+func (e entry[T]) inject(c *Container, ptr any) (any, error) {
+	entries := gather[entry[T]](c)
+	values := make(map[string]T, len(entries))
+	for _, cb := range entries {
+		var v T
+		if _, err := cb.p.p.f(cb.ctx, &v); err != nil {
+			return nil, fmt.Errorf("map entry %q: %w", cb.p.key, err)
+		}
+		values[cb.p.key] = v
+	}
+	*ptr.(*map[string]T) = values
+	return values, nil
+}
+
+// unwrap returns the provider of the entry's value.
+func (e entry[T]) unwrap() Provider { return e.p }
+
+// target returns the map injection target when the entry is a lazy singleton.
+func (e entry[T]) target() any {
+	if e.p.target() != nil {
+		return new(map[string]T)
+	}
+	return nil
+}
+
+// location describes the registration site skip frames above its caller as file:line.
+func location(skip int) string {
+	if _, file, line, ok := runtime.Caller(skip + 1); ok {
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	return "unknown"
+}
+
+// newEntry creates a map entry, recording the caller of ProvideMap/BuildMap.
+func newEntry[T any](key string, p Provider) entry[T] {
+	return entry[T]{entryKey[T]{key, location(2)}, atomic.AddInt64(&sequence, 1), p.(provider[T])}
+}
+
+// ProvideMap creates a Provider contributing the value v under key to the map of type T.
+// Injecting map[string]T collects the entries of the container and its nested containers.
+// Registering the same key twice fails at Add time, naming both registrations.
+// Example: ProvideMap[PaymentProvider]("stripe", stripe.New())
+func ProvideMap[T any](key string, v T) Provider {
+	return newEntry[T](key, Provide(v))
+}
+
+// BuildMap is like ProvideMap but contributes a lazy singleton built by f.
+// Example: BuildMap("s3", func(cfg Config) (Storage, error) { return s3.New(cfg.Bucket) })
+func BuildMap[R, T any](key string, f func(R) (T, error), opts ...BuildOption) Provider {
+	return newEntry[T](key, Build(f, opts...))
+}
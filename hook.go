@@ -2,9 +2,15 @@ package godi
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"sync/atomic"
 )
 
+// Callbacks passes the callbacks recorded by a hook to the given function. Callbacks
+// are ordered by dependency: the callback of a provider comes after those of the
+// providers it depends on, whatever the order of injection, so iterating in reverse
+// stops each component before the things it depends on.
 type Callbacks func(func([]func(ctx context.Context)))
 
 func (callbacks Callbacks) Iterate(ctx context.Context, reverse bool) {
@@ -19,31 +25,178 @@ func (callbacks Callbacks) Iterate(ctx context.Context, reverse bool) {
 }
 
 func (c *Container) Hook(name string, build func(v any, provided int) func(ctx context.Context)) Callbacks {
+	return c.HookErr(name, func(v any, provided int) func(ctx context.Context) error {
+		if fn := build(v, provided); fn != nil {
+			return func(ctx context.Context) error { fn(ctx); return nil }
+		}
+		return nil
+	})
+}
+
+func (c *Container) HookOnce(name string, build func(v any) func(ctx context.Context)) Callbacks {
+	return c.Hook(name, func(v any, provided int) func(ctx context.Context) {
+		if provided > 0 {
+			return nil
+		}
+		return build(v)
+	})
+}
+
+// HookErr is Hook with callbacks returning an error, reported by Callbacks.IterateErr
+// with the instance the callback was built for. Iterate ignores the errors.
+func (c *Container) HookErr(name string, build func(v any, provided int) func(ctx context.Context) error) Callbacks {
+	return c.hook(name, name, func(_, v any, provided int) func(ctx context.Context) error { return build(v, provided) })
+}
+
+// hook registers the hook stored under key, replacing any hook with the same key.
+// build receives the provider id of the injected value.
+func (c *Container) hook(key any, name string, build func(id, v any, provided int) func(ctx context.Context) error) Callbacks {
 	c.once.Do(func() { c.hooks = new(sync.Map) })
 	mu := sync.Mutex{}
 	called := make(map[any]int)
-	fns := make([]func(context.Context), 0)
-	c.hooks.Store(name, func(id, v any) {
+	type recorded struct {
+		id  any
+		seq int64
+		fn  func(context.Context)
+	}
+	fns := make([]recorded, 0)
+	c.hooks.Store(key, func(id, v any) {
 		mu.Lock()
 		defer mu.Unlock()
-		if fn := build(v, called[id]); fn != nil {
-			fns = append(fns, fn)
+		if fn := build(id, v, called[id]); fn != nil {
+			fns = append(fns, recorded{id, atomic.AddInt64(&sequence, 1), func(ctx context.Context) {
+				cl, _ := ctx.Value(callKey{}).(*call)
+				cl.start(name, id, v)
+				cl.finish(fn(ctx))
+			}})
 		}
 		called[id]++
 	})
 	return func(f func([]func(ctx context.Context))) {
 		mu.Lock()
-		cbs := append(make([]func(context.Context), 0, len(fns)), fns...)
+		all := append(make([]recorded, 0, len(fns)), fns...)
 		mu.Unlock()
-		f(cbs)
+		rank := c.dependencyRank()
+		cbs, at := make([]func(context.Context), len(all)), make([]position, len(all))
+		for i, r := range all {
+			cbs[i], at[i] = r.fn, position{rank(r.id), r.seq}
+		}
+		pass(f, cbs, at)
 	}
 }
 
-func (c *Container) HookOnce(name string, build func(v any) func(ctx context.Context)) Callbacks {
-	return c.Hook(name, func(v any, provided int) func(ctx context.Context) {
+// position is the place of a callback in dependency order: the rank of its provider
+// (see Container.dependencyRank), then the order in which callbacks were recorded.
+type position struct {
+	rank int
+	seq  int64
+}
+
+func (p position) before(o position) bool {
+	return p.rank < o.rank || p.rank == o.rank && p.seq < o.seq
+}
+
+// unranked is the position of callbacks not built by a hook: after all others.
+var unranked = position{int(^uint(0) >> 1), 1<<63 - 1}
+
+// joinable is a list of callbacks sorted by position, as handed to JoinCallbacks.
+type joinable struct {
+	fns []func(ctx context.Context)
+	at  []position
+}
+
+// pass sorts fns by position and passes them to f. A nil f is JoinCallbacks asking
+// for the positions too: they are handed over by panicking with a *joinable.
+func pass(f func([]func(ctx context.Context)), fns []func(ctx context.Context), at []position) {
+	sort.Sort(byPosition{fns, at})
+	if f == nil {
+		panic(&joinable{fns, at})
+	}
+	f(fns)
+}
+
+// joinableOf returns the callbacks of callbacks with their positions. Callbacks of hooks
+// and JoinCallbacks hand them over when called with a nil function (see pass); other
+// Callbacks panic calling it, and their callbacks are placed after all others.
+func joinableOf(callbacks Callbacks) (j *joinable) {
+	func() {
+		defer func() { j, _ = recover().(*joinable) }()
+		callbacks(nil)
+	}()
+	if j == nil {
+		j = new(joinable)
+		callbacks(func(fns []func(ctx context.Context)) {
+			for _, fn := range fns {
+				j.fns, j.at = append(j.fns, fn), append(j.at, unranked)
+			}
+		})
+	}
+	return j
+}
+
+type byPosition struct {
+	fns []func(ctx context.Context)
+	at  []position
+}
+
+func (b byPosition) Len() int           { return len(b.fns) }
+func (b byPosition) Less(i, j int) bool { return b.at[i].before(b.at[j]) }
+func (b byPosition) Swap(i, j int) {
+	b.fns[i], b.fns[j] = b.fns[j], b.fns[i]
+	b.at[i], b.at[j] = b.at[j], b.at[i]
+}
+
+// HookOnceErr is HookOnce with callbacks returning an error (see HookErr).
+func (c *Container) HookOnceErr(name string, build func(v any) func(ctx context.Context) error) Callbacks {
+	return c.HookErr(name, func(v any, provided int) func(ctx context.Context) error {
 		if provided > 0 {
 			return nil
 		}
 		return build(v)
 	})
 }
+
+// HookInfo describes the injection a typed hook fires for (see HookFor).
+type HookInfo struct {
+	Name     string // Hook name
+	Type     string // Provided type, e.g. *main.Database or main.Database "replica"
+	Provided int    // Number of previous injections of the type through the container (0 = first)
+}
+
+// typedHook is the key of a hook registered by HookFor, distinct for each type T.
+type typedHook[T any] struct{ name string }
+
+// HookFor registers a hook firing only for injected values of type T, or implementing T
+// when T is an interface, so build needs no type switch. Typed hooks are stored by name
+// and type: HookFor[*Database](c, "shutdown", ...) and HookFor[Cache](c, "shutdown", ...)
+// coexist, and so do untyped hooks of the same name. The returned Callbacks iterate like
+// the ones of Hook and can be combined with them by JoinCallbacks.
+// Example: HookFor(c, "shutdown", func(db *Database, info HookInfo) func(context.Context) { ... })
+func HookFor[T any](c *Container, name string, build func(v T, info HookInfo) func(ctx context.Context)) Callbacks {
+	return c.hook(typedHook[T]{name}, name, func(id, v any, provided int) func(ctx context.Context) error {
+		t, ok := v.(T)
+		if !ok {
+			return nil
+		}
+		if fn := build(t, HookInfo{Name: name, Type: typeOf(id), Provided: provided}); fn != nil {
+			return func(ctx context.Context) error { fn(ctx); return nil }
+		}
+		return nil
+	})
+}
+
+// JoinCallbacks combines callbacks, e.g. of typed and untyped hooks, to iterate them
+// together. The callbacks of every hook are merged in dependency order, like those of
+// a single hook, and in recording order for callbacks of the same type; callbacks not
+// built by a hook come last. Callbacks not returned by a hook or JoinCallbacks are
+// first called with a nil function, which they are expected to panic on.
+func JoinCallbacks(all ...Callbacks) Callbacks {
+	return func(f func([]func(ctx context.Context))) {
+		var joined joinable
+		for _, callbacks := range all {
+			j := joinableOf(callbacks)
+			joined.fns, joined.at = append(joined.fns, j.fns...), append(joined.at, j.at...)
+		}
+		pass(f, joined.fns, joined.at)
+	}
+}
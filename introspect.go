@@ -0,0 +1,108 @@
+package godi
+
+import (
+	"sort"
+	"strconv"
+)
+
+// walk visits the providers of c and of its nested child containers in registration
+// order, each child container right before its own providers, with the path of the
+// owning container (root, root/0, root/0/1...). Children are numbered in registration
+// order too. Decorators are skipped: they provide no type. Walking stops when visit
+// returns false.
+func (c *Container) walk(visit func(id any, p Provider, owner string) bool) {
+	visited := map[*Container]bool{c: true}
+	var collect func(src *Container, owner string) bool
+	collect = func(src *Container, owner string) bool {
+		type registered struct {
+			id  any
+			p   Provider
+			seq int64
+		}
+		var all []registered
+		src.providers.Range(func(id, p any) bool {
+			if _, decorator := p.(decorating); id != locked && !decorator {
+				seq, _ := src.order.Load(id)
+				n, _ := seq.(int64)
+				all = append(all, registered{id, p.(Provider), n})
+			}
+			return true
+		})
+		sort.Slice(all, func(i, j int) bool { return all[i].seq < all[j].seq })
+
+		children := 0
+		for _, r := range all {
+			sub, isSub := r.p.(*Container)
+			if isSub && visited[sub] {
+				continue
+			}
+			if !visit(r.id, r.p, owner) {
+				return false
+			}
+			if isSub {
+				visited[sub] = true
+				if !collect(sub, owner+"/"+strconv.Itoa(children)) {
+					return false
+				}
+				children++
+			}
+		}
+		return true
+	}
+	collect(c, "root")
+}
+
+// ProviderInfo describes a provider registered in a container tree (see Container.Providers).
+type ProviderInfo struct {
+	Type  string // Provided type, e.g. *main.Database, main.Database "replica" or []main.HealthCheck
+	Kind  Kind   // How the provider constructs values, KindContainer for child containers
+	Built bool   // Whether the singleton of a Provide or Build provider has been constructed
+	Err   error  // Construction error of the singleton, or last failure of one to retry (Retry)
+	Owner string // Path of the owning container: root, root/0, root/0/1...
+
+	// Aliases lists the interfaces the provider is also reachable as (see Bind),
+	// e.g. main.UserRepository.
+	Aliases []string
+}
+
+// Walk calls fn for every provider registered in c and its nested child containers,
+// in registration order; a child container is reported (with KindContainer) right
+// before its own providers. Walking stops when fn returns false.
+// Singletons report the state of the base container: values rebuilt by an Overlay
+// are not reflected.
+func (c *Container) Walk(fn func(ProviderInfo) bool) {
+	c.walk(func(id any, p Provider, owner string) bool {
+		pi := ProviderInfo{Type: typeOf(id), Owner: owner}
+		if _, sub := p.(*Container); sub {
+			pi.Type, pi.Kind = "*godi.Container", KindContainer
+			return fn(pi)
+		}
+		pi.Aliases = aliasesOf(p)
+		if i := infoOf(p); i != nil {
+			pi.Kind = i.kind
+			if i.l != nil {
+				pi.Built, pi.Err = i.l.state()
+			}
+		}
+		return fn(pi)
+	})
+}
+
+// Providers lists every provider registered in c and its nested child containers,
+// in registration order (see Walk).
+func (c *Container) Providers() (all []ProviderInfo) {
+	c.Walk(func(pi ProviderInfo) bool { all = append(all, pi); return true })
+	return
+}
+
+// aliasesOf returns the interface type names p is bound to with Bind.
+// Group members are not listed under []T again.
+func aliasesOf(p Provider) (names []string) {
+	all := ids(p)
+	for _, id := range all[1:] {
+		if name := typeOf(id); name != typeOf(all[0]) {
+			names = append(names, name)
+		}
+	}
+	return
+}
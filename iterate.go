@@ -0,0 +1,222 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// HookError is the failure of a hook callback, with the injected instance the
+// callback was built for (see Callbacks.Run).
+type HookError struct {
+	Hook     string // Hook name, or "callback #i" for callbacks not built by a hook
+	Type     string // Provided type of the instance, e.g. *main.Database
+	Instance any    // Injected instance
+	Err      error  // Error returned by the callback, or why it did not return
+}
+
+func (e *HookError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("hook %s: %v", e.Hook, e.Err)
+	}
+	return fmt.Sprintf("hook %s [%s]: %v", e.Hook, e.Type, e.Err)
+}
+
+func (e *HookError) Unwrap() error { return e.Err }
+
+// IterateOption configures Callbacks.Run and Callbacks.IterateErr.
+type IterateOption func(*iterateOptions)
+
+type iterateOptions struct {
+	stopOnError bool
+	timeout     time.Duration
+	parallel    int
+}
+
+// StopOnError skips the remaining callbacks after the first failure.
+func StopOnError() IterateOption {
+	return func(o *iterateOptions) { o.stopOnError = true }
+}
+
+// CallbackTimeout bounds the time given to each callback. Its context is canceled
+// after d and a callback still running is reported as failed and abandoned:
+// iteration goes on without waiting for it.
+func CallbackTimeout(d time.Duration) IterateOption {
+	return func(o *iterateOptions) { o.timeout = d }
+}
+
+// Parallel runs up to n callbacks at a time, for callbacks independent of each other.
+// Callbacks are started in iteration order but may complete in any order.
+func Parallel(n int) IterateOption {
+	return func(o *iterateOptions) {
+		if n > 0 {
+			o.parallel = n
+		}
+	}
+}
+
+// callKey carries the call of a single callback in the context given by Run.
+type callKey struct{}
+
+// call records which instance a callback runs for and how it ended.
+// The instance is recorded before the callback runs, so a stuck callback can be named.
+type call struct {
+	mu       sync.Mutex
+	hook     string
+	id       any
+	instance any
+	err      error
+}
+
+// start records the instance a hook callback runs for. cl may be nil when the
+// callback is not run by Run.
+func (cl *call) start(hook string, id, v any) {
+	if cl != nil {
+		cl.mu.Lock()
+		cl.hook, cl.id, cl.instance = hook, id, v
+		cl.mu.Unlock()
+	}
+}
+
+// finish records the result of a hook callback.
+func (cl *call) finish(err error) {
+	if cl != nil {
+		cl.mu.Lock()
+		cl.err = err
+		cl.mu.Unlock()
+	}
+}
+
+// result returns the failure of the callback at index i, or nil, and its name.
+func (cl *call) result(i int, err error) (string, *HookError) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	hook, typ := cl.hook, ""
+	if hook == "" {
+		hook = "callback #" + strconv.Itoa(i)
+	} else {
+		typ = typeOf(cl.id)
+	}
+	if err == nil {
+		err = cl.err
+	}
+	name := hook
+	if typ != "" {
+		name += " [" + typ + "]"
+	}
+	if err == nil {
+		return name, nil
+	}
+	return name, &HookError{Hook: hook, Type: typ, Instance: cl.instance, Err: err}
+}
+
+// run calls fn with the call record in its context, within the callback timeout if any.
+func (o *iterateOptions) run(ctx context.Context, i int, fn func(ctx context.Context)) (string, *HookError) {
+	cl := new(call)
+	if o.timeout <= 0 {
+		fn(context.WithValue(ctx, callKey{}, cl))
+		return cl.result(i, nil)
+	}
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(context.WithValue(ctx, callKey{}, cl))
+	}()
+	select {
+	case <-done:
+		return cl.result(i, nil)
+	case <-ctx.Done():
+		return cl.result(i, fmt.Errorf("callback did not return: %w", ctx.Err()))
+	}
+}
+
+// Summary reports what Callbacks.Run did.
+type Summary struct {
+	Ran     []string     // Callbacks that succeeded, e.g. "shutdown [*main.Database]"
+	Failed  []*HookError // Callbacks that failed or did not return in time
+	Skipped int          // Callbacks not run because of cancellation or StopOnError
+	Err     error        // Context error that caused callbacks to be skipped, if any
+}
+
+// Errors returns every failure as Errors (including the cancellation), or nil.
+func (s *Summary) Errors() error {
+	var errs Errors
+	for _, e := range s.Failed {
+		errs = append(errs, e)
+	}
+	if s.Err != nil {
+		errs = append(errs, fmt.Errorf("%d callbacks skipped: %w", s.Skipped, s.Err))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Run runs the callbacks like Iterate and reports what ran, failed or was skipped.
+// Failures are those of callbacks built by HookErr and HookOnceErr and callbacks not
+// returning within CallbackTimeout. Cancellation of ctx is checked between callbacks:
+// once ctx is done, the remaining callbacks are skipped.
+// Callbacks keep running after a failure unless StopOnError is given.
+func (callbacks Callbacks) Run(ctx context.Context, reverse bool, opts ...IterateOption) *Summary {
+	o := &iterateOptions{parallel: 1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := new(Summary)
+	callbacks(func(fns []func(ctx context.Context)) {
+		var (
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+			stopped bool
+		)
+		slots := make(chan struct{}, o.parallel)
+		for v, i, l := 0, 0, len(fns); i < l; i++ {
+			if v = i; reverse {
+				v = l - i - 1
+			}
+			slots <- struct{}{}
+			mu.Lock()
+			halt := stopped
+			if err := ctx.Err(); err != nil && !halt {
+				s.Err, halt = err, true
+			}
+			if halt {
+				s.Skipped = l - i
+			}
+			mu.Unlock()
+			if halt {
+				break
+			}
+			wg.Add(1)
+			runOne := func(v int) {
+				defer func() { <-slots; wg.Done() }()
+				name, failure := o.run(ctx, v, fns[v])
+				mu.Lock()
+				defer mu.Unlock()
+				if failure == nil {
+					s.Ran = append(s.Ran, name)
+				} else if s.Failed = append(s.Failed, failure); o.stopOnError {
+					stopped = true
+				}
+			}
+			if o.parallel > 1 {
+				go runOne(v)
+			} else {
+				runOne(v)
+			}
+		}
+		wg.Wait()
+	})
+	return s
+}
+
+// IterateErr runs the callbacks like Run and returns every failure as Errors of
+// *HookError, in completion order, plus the cancellation that skipped callbacks.
+func (callbacks Callbacks) IterateErr(ctx context.Context, reverse bool, opts ...IterateOption) error {
+	return callbacks.Run(ctx, reverse, opts...).Errors()
+}
@@ -0,0 +1,143 @@
+package godi
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Starter is implemented by components started by Container.Start.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by components stopped by Container.Stop.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// component is a singleton built by the container whose lifecycle Start and Stop manage.
+// It is identified by the instance caching it: BuildGroup members share a name.
+type component struct {
+	name  string
+	value any
+	l     *instance
+}
+
+// components returns the singletons built by Build providers and owned by c, each
+// after the components it depends on (see ordered). Overlays own their own copies of
+// the singletons, while scopes created by NewScope own none: they share their parent's.
+func (c *Container) components() (built []component) {
+	if c.scope != nil && !c.scope.overlay {
+		return nil
+	}
+	all, order := c.ordered()
+	for _, i := range order {
+		in := infoOf(all[i].p)
+		if in == nil || in.kind != KindBuild || in.l == nil {
+			continue
+		}
+		l, err := c.scope.singleton(in.l)
+		if err != nil {
+			continue
+		}
+		if ok, err := l.state(); ok && err == nil {
+			built = append(built, component{all[i].id(), l.value, l})
+		}
+	}
+	return
+}
+
+// Start builds every lazy singleton visible from c (see Warmup), then calls
+// Start(ctx) on the built components implementing Starter, in dependency order:
+// a component starts after everything it depends on.
+// If a component fails to start, the components started by this call are stopped
+// in reverse order and every failure is returned as Errors.
+// Components already started by a previous call, or shut down by Stop, are not
+// started again, so calling Start again only starts the components built since.
+// Only values constructed by the container (Build providers) are managed.
+func (c *Container) Start(ctx context.Context) error {
+	if err := c.Warmup(ctx, 0); err != nil {
+		return err
+	}
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+	started := make(map[*instance]bool, len(c.started))
+	for _, cp := range c.started {
+		started[cp.l] = true
+	}
+	first := len(c.started)
+	for _, cp := range c.components() {
+		s, ok := cp.value.(Starter)
+		if !ok || started[cp.l] || c.stopped[cp.l] {
+			continue
+		}
+		if err := s.Start(ctx); err != nil {
+			errs := Errors{fmt.Errorf("start %s: %w", cp.name, err)}
+			errs = append(errs, c.stopStarted(ctx, first)...)
+			return errs
+		}
+		delete(c.stopped, cp.l)
+		c.started = append(c.started, cp)
+	}
+	return nil
+}
+
+// stopStarted stops the components started by Start from index first on, in reverse
+// order. They are left to Stop for closing. It is called with the lifecycle lock held.
+func (c *Container) stopStarted(ctx context.Context, first int) (errs Errors) {
+	if c.stopped == nil {
+		c.stopped = make(map[*instance]bool)
+	}
+	for i := len(c.started) - 1; i >= first; i-- {
+		if s, ok := c.started[i].value.(Stopper); ok {
+			if err := s.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("stop %s: %w", c.started[i].name, err))
+			}
+		}
+		c.stopped[c.started[i].l] = false
+	}
+	c.started = c.started[:first]
+	return
+}
+
+// Stop shuts the built components down in reverse dependency order: a component
+// stops before everything it depends on. Components implementing Stopper get
+// Stop(ctx), whether or not they were started by Start, and components implementing
+// io.Closer are closed afterwards.
+// Each component is shut down once: calling Stop again only handles the components
+// built since.
+// All components are processed even if some fail; every failure is returned as Errors.
+func (c *Container) Stop(ctx context.Context) error {
+	c.lifecycle.Lock()
+	defer c.lifecycle.Unlock()
+	c.started = nil
+	if c.stopped == nil {
+		c.stopped = make(map[*instance]bool)
+	}
+
+	var errs Errors
+	all := c.components()
+	for i := len(all) - 1; i >= 0; i-- {
+		cp := all[i]
+		closed, halted := c.stopped[cp.l]
+		if closed {
+			continue
+		}
+		if s, ok := cp.value.(Stopper); ok && !halted {
+			if err := s.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("stop %s: %w", cp.name, err))
+			}
+		}
+		if closer, ok := cp.value.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close %s: %w", cp.name, err))
+			}
+		}
+		c.stopped[cp.l] = true
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package godi
+
+import "fmt"
+
+// qualifier is the provider id of a named provider: the pair (T, name).
+type qualifier[T any] struct{ name string }
+
+// qualifiedName renders the id as [T "name"] for error messages.
+func (q qualifier[T]) qualifiedName() string {
+	return "[" + fmt.Sprintf("%T", (*T)(nil))[1:] + fmt.Sprintf(" %q]", q.name)
+}
+
+// qualified is the injection target used to look up a named provider.
+type qualified[T any] struct {
+	qualifier[T]
+	ptr *T
+}
+
+// named is a Provider registered under a name, wrapping a regular provider of T.
+type named[T any] struct {
+	qualifier[T]
+	p provider[T]
+}
+
+// Provide matches both the id and the injection target of the same (T, name) pair.
+func (n named[T]) Provide(v any) (any, bool) {
+	switch q := v.(type) {
+	case qualifier[T]:
+		return n.qualifier, q == n.qualifier
+	case *qualified[T]:
+		return n.qualifier, q.qualifier == n.qualifier
+	}
+	return n.qualifier, false
+}
+
+// inject runs the wrapped provider, adding the name to its errors.
+func (n named[T]) inject(c *Container, ptr any) (any, error) {
+	v, err := n.p.f(c, ptr.(*qualified[T]).ptr)
+	if err != nil {
+		return v, fmt.Errorf("named %q: %w", n.name, err)
+	}
+	return v, nil
+}
+
+// target returns the lookup target of the named provider when it builds a lazy singleton.
+func (n named[T]) target() any {
+	if ptr, ok := n.p.target().(*T); ok {
+		return &qualified[T]{n.qualifier, ptr}
+	}
+	return nil
+}
+
+// unwrap returns the wrapped provider.
+func (n named[T]) unwrap() Provider { return n.p }
+
+// ProvideNamed creates a Provider that returns a pre-existing value under a name.
+// Several providers of the same type can coexist as long as their names differ.
+// Example: ProvideNamed("replica", replicaDB)
+func ProvideNamed[T any](name string, v T) Provider {
+	return named[T]{qualifier[T]{name}, Provide(v).(provider[T])}
+}
+
+// BuildNamed is like Build but registers the lazy singleton under a name.
+// Example: BuildNamed("replica", func(cfg Config) (*sql.DB, error) { return sql.Open("mysql", cfg.ReplicaDSN) })
+func BuildNamed[R, T any](name string, f func(R) (T, error), opts ...BuildOption) Provider {
+	return named[T]{qualifier[T]{name}, Build(f, opts...).(provider[T])}
+}
+
+// InjectNamedTo injects the provider of T registered under name into ptr.
+func InjectNamedTo[T any](c *Container, name string, ptr *T) (err error) {
+	id := qualifier[T]{name}
+	// Try direct provider first
+	if p, ok := c.providers.Load(id); ok {
+		_, err = c.from(p.(Provider), id, &qualified[T]{id, ptr}, nil)
+	} else {
+		// Search container hierarchy
+		_, err = c.inject(c, &qualified[T]{id, ptr})
+	}
+	return
+}
+
+// InjectNamed retrieves the dependency of type T registered under name.
+func InjectNamed[T any](c *Container, name string) (v T, _ error) {
+	return v, InjectNamedTo[T](c, name, &v)
+}
+
+// MustInjectNamed is like InjectNamed but panics on error.
+func MustInjectNamed[T any](c *Container, name string) (v T) {
+	must(InjectNamedTo[T](c, name, &v))
+	return
+}
+
+// Qualifier is implemented by marker types that name a dependency for Named.
+// Example:
+//
+//	type Replica struct{}
+//	func (Replica) Name() string { return "replica" }
+type Qualifier interface {
+	Name() string
+}
+
+// Named is a dependency form resolving the provider of T registered under N's name.
+// It can be used as a Build argument or inside Deps2..Deps6.
+// Example: Build(func(db Named[*sql.DB, Replica]) (*Reports, error) { return NewReports(db.Value), nil })
+type Named[T any, N Qualifier] struct {
+	Value T
+}
+
+func (d *Named[T, N]) declare() []requirement {
+	var n N
+	return []requirement{{id: qualifier[T]{n.Name()}}}
+}
+
+func (d *Named[T, N]) resolve(c *Container) error {
+	var n N
+	return InjectNamedTo(c, n.Name(), &d.Value)
+}
@@ -0,0 +1,70 @@
+package godi
+
+import "errors"
+
+// Lookup ranks: when several providers of a container match, the highest rank wins.
+const (
+	rankContainer = iota + 1
+	rankProvider
+	rankOverride
+)
+
+// override marks a provider registered with Override.
+type override struct {
+	Provider
+}
+
+// aliases keeps the interface aliases of the replacement provider.
+func (o override) aliases() []any { return ids(o.Provider)[1:] }
+
+// unwrap returns the replacement provider.
+func (o override) unwrap() Provider { return o.Provider }
+
+// target returns the injection target of the replacement provider.
+func (o override) target() any { return targetOf(o.Provider) }
+
+// rankOf returns the lookup rank of a provider.
+func rankOf(p Provider) int {
+	switch p.(type) {
+	case override:
+		return rankOverride
+	case *Container:
+		return rankContainer
+	}
+	return rankProvider
+}
+
+// Override registers providers that replace existing ones instead of failing on duplicates.
+// A replacement shadows the provider of the same type anywhere in the container tree,
+// including inside frozen child containers. Singletons already built keep their dependencies.
+// To replace dependencies without mutating the original container, override an Overlay.
+// Example: c.Overlay().Override(Provide[UserRepository](&MockRepo{}))
+func (c *Container) Override(pvs ...Provider) error {
+	if err := c.lock(); err != nil {
+		return err
+	}
+	defer c.providers.Delete(locked)
+
+	for _, p := range pvs {
+		id, _ := p.Provide(nil)
+		if _, ok := id.(*Container); ok {
+			return errors.New("override: child containers cannot be overridden")
+		}
+		c.register(id, override{p})
+	}
+	return nil
+}
+
+// MustOverride is like Override but panics on error.
+func (c *Container) MustOverride(pvs ...Provider) *Container { must(c.Override(pvs...)); return c }
+
+// Overlay creates a container shadowing c, typically for tests and environment variants.
+// Everything registered in c is visible through the overlay, and Override on the overlay
+// replaces types of c, even inside frozen child containers.
+// The original container is never mutated: singletons resolved through the overlay are
+// built again and cached in the overlay, and the hooks of c do not fire.
+func (c *Container) Overlay() *Container {
+	o := &Container{scope: &scope{parent: c.scope, overlay: true, instances: make(map[any]*instance)}}
+	o.register(c, c)
+	return o
+}
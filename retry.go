@@ -0,0 +1,63 @@
+package godi
+
+import "time"
+
+// BuildOption configures a Provider created by Build, BuildCtx, Build2..Build6,
+// BuildNamed, BuildGroup, BuildMap or Scoped (see Retry, Backoff and Requires).
+// Factory only accepts Requires: it caches nothing to retry.
+type BuildOption func(*buildOptions)
+
+// buildOptions holds the configuration collected from BuildOption values.
+type buildOptions struct {
+	retries  bool
+	attempts int           // Maximum number of constructions, 0 means unlimited
+	backoff  time.Duration // Delay after the first failure, doubled after each one
+	requires []requirement // Dependencies declared with Requires
+}
+
+// newBuildOptions applies opts, returning nil when there are none.
+func newBuildOptions(opts []BuildOption) *buildOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	o := new(buildOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Retry makes failed constructions retried on the next injection instead of being
+// cached for the lifetime of the provider; successful values stay singletons.
+// attempts limits the number of constructions: once reached, the last error is cached.
+// Zero means unlimited attempts.
+// Example: Build(dialDB, Retry(5), Backoff(time.Second))
+func Retry(attempts int) BuildOption {
+	return func(o *buildOptions) { o.retries, o.attempts = true, attempts }
+}
+
+// Backoff delays retries of failed constructions (see Retry): injections within d of a
+// failure return its error without constructing, and d doubles after each consecutive failure.
+func Backoff(d time.Duration) BuildOption {
+	return func(o *buildOptions) { o.backoff = d }
+}
+
+// retry records the failed construction of l and reports whether it can be retried.
+// It is called with l.mu held.
+func (o *buildOptions) retry(l *instance, err error) bool {
+	if o == nil || !o.retries {
+		return false
+	}
+	if l.failures++; o.attempts > 0 && l.failures >= o.attempts {
+		return false
+	}
+	l.err = err
+	if o.backoff > 0 {
+		d := o.backoff
+		for i := 1; i < l.failures && d < time.Hour; i++ {
+			d *= 2
+		}
+		l.retryAt = time.Now().Add(d)
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package godi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// scope holds the instances built by Scoped providers for one scope container.
+// Overlays are scopes that also own the singletons resolved through them.
+type scope struct {
+	mu        sync.Mutex
+	parent    *scope            // Enclosing scope or overlay
+	overlay   bool              // Set by Overlay
+	instances map[any]*instance // Provider key → instance in this scope
+	owned     []any             // Built values in construction order
+	disposed  bool
+}
+
+// instance is a lazily built value: a singleton, or a value owned by a scope.
+type instance struct {
+	mu       sync.Mutex
+	wait     chan struct{} // Closed when the construction in progress ends
+	built    bool
+	value    any
+	err      error     // Cached error, or last error of a construction to retry
+	failures int       // Failed constructions so far (Retry option)
+	retryAt  time.Time // No construction before (Backoff option)
+}
+
+// do returns the value built by f, running f only once like sync.Once.
+// Callers waiting for a construction started by another caller give up when their own
+// ctx is done, and a construction failing because its ctx is done is not cached:
+// the next caller builds again. Options o may allow retrying other failures too.
+func (l *instance) do(ctx context.Context, ptr any, o *buildOptions, f func() (any, error)) (any, error) {
+	for {
+		l.mu.Lock()
+		if l.built || l.err != nil && time.Now().Before(l.retryAt) {
+			l.mu.Unlock()
+			return l.value, l.err
+		}
+		if wait := l.wait; wait != nil {
+			l.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				// The type requested and its path are filled by Container.from
+				return nil, &CanceledError{Err: ctx.Err()}
+			}
+		}
+		l.wait = make(chan struct{})
+		l.mu.Unlock()
+		return l.run(ctx, o, f)
+	}
+}
+
+// state reports whether the value has been built, and the construction error if any.
+func (l *instance) state() (built bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.built, l.err
+}
+
+// run executes the construction started by do and releases the waiting callers.
+func (l *instance) run(ctx context.Context, o *buildOptions, f func() (any, error)) (v any, err error) {
+	defer func() {
+		e := recover()
+		if e != nil {
+			v, err = nil, fmt.Errorf("constructor panic: %v", e)
+		}
+		l.mu.Lock()
+		if err == nil || ctx.Err() == nil && !o.retry(l, err) {
+			l.built, l.value, l.err = true, v, err
+		}
+		close(l.wait)
+		l.wait = nil
+		l.mu.Unlock()
+		if e != nil {
+			panic(e) // Reported by recoverBuild to this caller
+		}
+	}()
+	return f()
+}
+
+// load returns the instance of the Scoped provider identified by key.
+func (s *scope) load(key any) (*instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.disposed {
+		return nil, errors.New("scope disposed")
+	}
+	l, ok := s.instances[key]
+	if !ok {
+		l = new(instance)
+		s.instances[key] = l
+	}
+	return l, nil
+}
+
+// isolated returns the nearest overlay of the scope chain, or nil.
+func (s *scope) isolated() *scope {
+	for ; s != nil; s = s.parent {
+		if s.overlay {
+			return s
+		}
+	}
+	return nil
+}
+
+// singleton returns the instance caching the singleton identified by l:
+// l itself, or the copy owned by the nearest overlay.
+func (s *scope) singleton(l *instance) (*instance, error) {
+	if o := s.isolated(); o != nil {
+		return o.load(l)
+	}
+	return l, nil
+}
+
+// own records a value built in this scope so Dispose can clean it up.
+func (s *scope) own(v any) {
+	s.mu.Lock()
+	s.owned = append(s.owned, v)
+	s.mu.Unlock()
+}
+
+// Scoped creates a Provider that constructs a value once per scope.
+// The factory function f supports the same dependency patterns as Build.
+// Scoped providers can only be injected through a container created by NewScope;
+// every scope gets its own instance, which is cleaned up by Dispose.
+// Build options apply to the instance of each scope (see Retry and Requires).
+// Note: Build singletons must not depend on Scoped values, they would keep the first scope's instance.
+// Example: Scoped(func(db *Database) (*Tx, error) { return db.Begin() })
+func Scoped[R, T any](f func(R) (T, error), opts ...BuildOption) Provider {
+	key, o := new(byte), newBuildOptions(opts) // key identifies this provider inside every scope
+	return provider[T]{info: &info{kind: KindScoped, deps: append(declare[R](), o.required()...)}, f: func(c *Container, ptr *T) (zero T, err error) {
+		defer recoverBuild(ptr, &err)
+		if c.scope == nil {
+			return zero, fmt.Errorf("scoped provider %s requires a scope: use Container.NewScope", typName(ptr))
+		}
+		v, e := resolve[R](c)
+		if e != nil {
+			return zero, e
+		}
+		l, e := c.scope.load(key)
+		if e != nil {
+			return zero, buildError(c, ptr, e)
+		}
+		// Execute factory function once per scope
+		value, e := l.do(c.Context(), ptr, o, func() (any, error) {
+			value, err := f(v)
+			if err == nil {
+				value, err = decorate(c, value)
+			}
+			if err != nil {
+				return nil, err
+			}
+			c.scope.own(value)
+			return value, nil
+		})
+		if e != nil {
+			return zero, buildError(c, ptr, e)
+		}
+		*ptr, _ = value.(T)
+		return *ptr, nil
+	}}
+}
+
+// NewScope creates a scope container on top of c (request, session, job lifetimes).
+// Everything registered in c is visible through the scope, Build singletons stay shared,
+// and Scoped providers are built once per scope.
+// Unlike Add, creating a scope does not freeze c, and providers can be added to the scope itself.
+func (c *Container) NewScope() *Container {
+	s := &Container{scope: &scope{parent: c.scope, instances: make(map[any]*instance)}}
+	s.register(c, c)
+	return s
+}
+
+// Dispose ends a scope created by NewScope.
+// Scope-owned instances implementing interface{ Close() error } are closed in reverse
+// construction order; shared singletons are left untouched.
+// All instances are closed even if some fail; the first error is returned.
+func (c *Container) Dispose() (err error) {
+	if c.scope == nil {
+		return errors.New("dispose: container is not a scope")
+	}
+	c.scope.mu.Lock()
+	owned := c.scope.owned
+	c.scope.owned, c.scope.instances, c.scope.disposed = nil, nil, true
+	c.scope.mu.Unlock()
+
+	for i := len(owned) - 1; i >= 0; i-- {
+		if closer, ok := owned[i].(interface{ Close() error }); ok {
+			if e := closer.Close(); e != nil && err == nil {
+				err = fmt.Errorf("dispose %T error: %w", owned[i], e)
+			}
+		}
+	}
+	return
+}
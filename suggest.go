@@ -0,0 +1,86 @@
+package godi
+
+import (
+	"sort"
+	"strings"
+)
+
+// zero returns the zero value of T, used to test interface implementations without reflection.
+func (p provider[T]) zero() any {
+	var v T
+	return v
+}
+
+// accepts reports whether v is assignable to T, i.e. whether T is an interface implemented by v.
+func (p provider[T]) accepts(v any) bool {
+	_, ok := v.(T)
+	return ok
+}
+
+// typed is implemented by plain providers of a single type.
+type typed interface {
+	zero() any
+	accepts(v any) bool
+}
+
+// binder is implemented by Bind providers.
+type binder interface {
+	aliases() []any
+	unwrap() Provider
+}
+
+// suggest lists the registered types visible from c that are close to the missing type:
+// pointer/value mismatches and the same type name in another package, then, given the zero
+// value of the missing type and a test for it, implementations of the missing interface and
+// interfaces implemented by the missing type.
+func (c *Container) suggest(missing string, zero any, implements func(v any) bool) []string {
+	found := make(map[string]bool)
+	c.walk(func(_ any, p Provider, _ string) bool {
+		if o, ok := p.(override); ok {
+			p = o.Provider
+		}
+		for _, id := range ids(p) {
+			if _, qualified := id.(interface{ qualifiedName() string }); qualified {
+				continue // Named providers, group members and map entries
+			}
+			if name := typeOf(id); name != missing {
+				switch {
+				case strings.TrimPrefix(name, "*") == strings.TrimPrefix(missing, "*"):
+					found[name+" (pointer/value mismatch)"] = true
+				case baseName(name) == baseName(missing):
+					found[name+" (same name, other package)"] = true
+				}
+			}
+		}
+		// Group members are only injected as []T: their value type is not suggested
+		if _, ok := p.(grouped); ok {
+			return true
+		}
+		// Interface checks need the value type: look through bindings to their base provider
+		for b, ok := p.(binder); ok; b, ok = p.(binder) {
+			p = b.unwrap()
+		}
+		if t, ok := p.(typed); ok && implements != nil {
+			id, _ := p.Provide(nil)
+			if v := t.zero(); v != nil && implements(v) {
+				found[typeOf(id)+" (implements "+missing+")"] = true
+			} else if zero != nil && t.accepts(zero) {
+				found[typeOf(id)+" (interface implemented by "+missing+")"] = true
+			}
+		}
+		return true
+	})
+
+	hints := make([]string, 0, len(found))
+	for h := range found {
+		hints = append(hints, h)
+	}
+	sort.Strings(hints)
+	return hints
+}
+
+// baseName strips the pointer and package qualifier from a type name: *pkg.Name becomes Name.
+func baseName(name string) string {
+	name = strings.TrimLeft(name, "*")
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,141 @@
+package godi
+
+// requirement is a dependency declared by a provider.
+type requirement struct {
+	id       any  // Lookup id of the dependency, e.g. (*T)(nil) or a named qualifier
+	optional bool // Optional: a missing provider is not an error
+	deferred bool // Lazy: resolved after construction, so it cannot close a cycle
+}
+
+// declarer is implemented by dependency forms to declare the dependencies they resolve.
+type declarer interface {
+	declare() []requirement
+}
+
+// declare returns the dependencies declared by the Build argument R.
+// The func(*Container) pattern declares nothing by itself, see Requires.
+func declare[R any]() []requirement {
+	var v R
+	switch d := any(&v).(type) {
+	case *struct{}, **Container:
+		return nil
+	case declarer:
+		return d.declare()
+	}
+	return []requirement{{id: (*R)(nil)}}
+}
+
+// concat joins the dependencies declared by the fields of a dependency form.
+func concat(all ...[]requirement) (reqs []requirement) {
+	for _, r := range all {
+		reqs = append(reqs, r...)
+	}
+	return
+}
+
+// requirementsOf returns the dependencies declared by p.
+func requirementsOf(p Provider) []requirement {
+	if i := infoOf(p); i != nil {
+		return i.deps
+	}
+	return nil
+}
+
+// Requires declares a dependency of T for Validate, for builders using the
+// func(*Container) pattern whose injections cannot be inferred from their signature.
+// It is accepted by every builder: Build, Factory, Scoped and their variants.
+// Example: Build(func(c *Container) (*Service, error) { ... }, Requires[*Database](), Requires[Config]())
+func Requires[T any]() BuildOption {
+	return func(o *buildOptions) { o.requires = append(o.requires, requirement{id: (*T)(nil)}) }
+}
+
+// required returns the dependencies declared with Requires, if any.
+func (o *buildOptions) required() []requirement {
+	if o == nil {
+		return nil
+	}
+	return o.requires
+}
+
+// Validate checks the dependency graph declared by the providers visible from c,
+// including nested child containers, without constructing anything.
+// It reports every missing dependency and every cycle as Errors of *ContainerError,
+// wrapping ErrNotFound and ErrCircular like the same failures at injection time.
+// Dependencies are declared by the Build argument type (single, Deps2..Deps6, Named,
+// Optional, Lazy) and by Requires for the func(*Container) pattern; Lazy dependencies
+// do not close cycles and missing Optional ones are allowed.
+// Decorators of a type no provider constructs, such as an interface only exposed with
+// Bind, never run: they are reported as ErrNotFound too.
+func (c *Container) Validate() error {
+	nodes := c.nodes()
+
+	var errs Errors
+	edges := make([][]int, len(nodes))
+	for i, n := range nodes {
+		for _, r := range requirementsOf(n.p) {
+			found := nodes.resolve(r.id)
+			if len(found) == 0 && !r.optional {
+				errs = append(errs, &ContainerError{Err: ErrNotFound, Type: typeOf(r.id), Path: []string{n.id(), typeOf(r.id)}, Container: c})
+			}
+			if !r.deferred {
+				edges[i] = append(edges[i], found...)
+			}
+		}
+	}
+
+	for _, d := range c.decorators() {
+		id, at := d.decorates()
+		matched := false
+		for _, n := range nodes {
+			if matched = constructs(n.p, id); matched {
+				break
+			}
+		}
+		if !matched {
+			errs = append(errs, &ContainerError{Err: ErrNotFound, Type: typeOf(id), Path: []string{"decorator at " + at, typeOf(id)}, Container: c})
+		}
+	}
+
+	// Depth-first search: reaching a node of the current path closes a cycle
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make([]int, len(nodes))
+	var path []int
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = visiting
+		path = append(path, i)
+		for _, j := range edges[i] {
+			switch state[j] {
+			case unvisited:
+				visit(j)
+			case visiting:
+				var names []string
+				for k := len(path) - 1; k >= 0; k-- {
+					if names = append(names, nodes[path[k]].id()); path[k] == j {
+						break
+					}
+				}
+				for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+					names[l], names[r] = names[r], names[l]
+				}
+				names = append(names, nodes[j].id())
+				errs = append(errs, &ContainerError{Err: ErrCircular, Type: nodes[j].id(), Path: names, Container: c})
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = done
+	}
+	for i := range nodes {
+		if state[i] == unvisited {
+			visit(i)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
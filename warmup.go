@@ -0,0 +1,121 @@
+package godi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Errors aggregates the failures of an operation covering several providers (see Warmup).
+// errors.Is and errors.As match any of the aggregated errors.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the aggregated errors matches target.
+func (e Errors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first aggregated error matching target.
+func (e Errors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetOf returns the injection target building the lazy singleton of p, or nil.
+func targetOf(p Provider) any {
+	if t, ok := p.(interface{ target() any }); ok {
+		return t.target()
+	}
+	return nil
+}
+
+// targets collects one injection target per lazy singleton visible from c,
+// walking nested child containers, ordered by type name.
+func (c *Container) targets() []any {
+	found := make(map[any]any)
+	visited := make(map[*Container]bool)
+	var collect func(src *Container)
+	collect = func(src *Container) {
+		src.providers.Range(func(_, p any) bool {
+			if sub, ok := p.(*Container); ok {
+				if sub != locked && !visited[sub] {
+					visited[sub] = true
+					collect(sub)
+				}
+			} else if ptr := targetOf(p.(Provider)); ptr != nil {
+				id, _ := p.(Provider).Provide(ptr)
+				found[id] = ptr
+			}
+			return true
+		})
+	}
+	collect(c)
+
+	ids := make([]any, 0, len(found))
+	for id := range found {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return typName(ids[i]) < typName(ids[j]) })
+	all := make([]any, len(ids))
+	for i, id := range ids {
+		all[i] = found[id]
+	}
+	return all
+}
+
+// Warmup eagerly builds every lazy singleton visible from c (Build, BuildNamed,
+// BuildGroup, BuildMap...), including those of nested child containers, so that
+// misconfiguration surfaces at boot rather than on the first request.
+// Independent constructors run in parallel, at most concurrency at a time (no limit
+// if concurrency <= 0); dependencies are built first by the resolution itself.
+// ctx flows through the constructors like with InjectCtx.
+// Every failure is returned at once as Errors.
+func (c *Container) Warmup(ctx context.Context, concurrency int) error {
+	targets := c.targets()
+	if concurrency <= 0 || concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+	tmp := c.withContext(ctx)
+	errs := make([]error, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, ptr := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, ptr any) {
+			defer func() { <-sem; wg.Done() }()
+			errs[i] = tmp.Inject(ptr)
+		}(i, ptr)
+	}
+	wg.Wait()
+
+	var failed Errors
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}